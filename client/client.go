@@ -0,0 +1,162 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultBaseURL is the API host used when no other base URL is configured.
+const DefaultBaseURL = "https://api.assemblyai.com"
+
+// Client talks to the AssemblyAI API on behalf of a single token. It holds no
+// global state, so several clients can be used side by side.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	retry      RetryPolicy
+}
+
+// Option configures a Client created with New.
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API host, e.g. a local mock.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimSuffix(baseURL, "/")
+		}
+	}
+}
+
+// WithHTTPClient replaces the underlying *http.Client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New returns a client authenticated with token.
+func New(token string, opts ...Option) *Client {
+	c := &Client{
+		token:      token,
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API host the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// call sends a request and returns the response body. Any non-2xx response
+// is turned into one of the typed errors in errors.go. GET and DELETE requests
+// are retried on any transient failure, other methods only when the API can't
+// have acted on them.
+func (c *Client) call(ctx context.Context, method string, path string, body io.Reader) ([]byte, error) {
+	mode := retryRejected
+	if method == "GET" || method == "DELETE" {
+		mode = retryTransient
+	}
+	return c.do(ctx, method, path, body, mode)
+}
+
+// send performs a single attempt of a request.
+func (c *Client) send(ctx context.Context, method string, path string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if sized, ok := body.(*io.SectionReader); ok {
+		req.ContentLength = sized.Size()
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", c.token)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp, data)
+	}
+	return data, nil
+}
+
+// Download starts downloading a file the API links to, such as redacted
+// audio, and returns its body and size, or -1 when unknown. The URLs are
+// signed, so the request doesn't carry the token.
+func (c *Client) Download(ctx context.Context, url string) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, &NetworkError{Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, 0, newAPIError(resp, data)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// callJSON sends v encoded as JSON, or no body when v is nil, and decodes the
+// response into out.
+func (c *Client) callJSON(ctx context.Context, method string, path string, v interface{}, out interface{}) ([]byte, error) {
+	var body io.Reader
+	if v != nil {
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+	data, err := c.call(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
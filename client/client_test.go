@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AssemblyAI/assemblyai-cli/fakeapi"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestSubmitTranscript(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v2/transcript" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "token" {
+			t.Errorf("Expected Authorization token, got %s.", r.Header.Get("Authorization"))
+		}
+		var params S.TranscribeParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.AudioURL != "https://example.com/audio.mp3" {
+			t.Errorf("Expected audio URL to be sent, got %s.", params.AudioURL)
+		}
+		w.Write([]byte(`{"id": "abc", "status": "queued", "unknown_field": true}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL))
+	transcript, err := client.SubmitTranscript(context.Background(), S.TranscribeParams{AudioURL: "https://example.com/audio.mp3"})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if *transcript.ID != "abc" || *transcript.Status != "queued" {
+		t.Errorf("Expected queued transcript abc, got %s %s.", *transcript.ID, *transcript.Status)
+	}
+	if !strings.Contains(string(transcript.Raw), "unknown_field") {
+		t.Errorf("Expected the raw response to be kept, got %s.", string(transcript.Raw))
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "Authentication error, API token missing/invalid"}`))
+	}))
+	defer server.Close()
+
+	client := New("invalid", WithBaseURL(server.URL))
+	_, err := client.GetAccount(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d.", apiErr.StatusCode)
+	}
+	if apiErr.Message != "Authentication error, API token missing/invalid" {
+		t.Errorf("Expected the server message, got %s.", apiErr.Message)
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		status int
+		check  func(error) bool
+	}{
+		{http.StatusBadRequest, func(err error) bool { var e *InvalidRequestError; return errors.As(err, &e) }},
+		{http.StatusUnauthorized, func(err error) bool { var e *AuthenticationError; return errors.As(err, &e) }},
+		{http.StatusTooManyRequests, func(err error) bool { var e *RateLimitError; return errors.As(err, &e) }},
+		{http.StatusBadGateway, func(err error) bool { var e *ServerError; return errors.As(err, &e) }},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "req-1")
+			w.WriteHeader(test.status)
+		}))
+		client := New("token", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}))
+		_, err := client.GetTranscript(context.Background(), "abc")
+		server.Close()
+		if !test.check(err) {
+			t.Errorf("Unexpected error type %T for status %d.", err, test.status)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.RequestID != "req-1" || apiErr.Path != "/v2/transcript/abc" {
+			t.Errorf("Expected request details for status %d, got %v.", test.status, err)
+		}
+	}
+
+	client := New("token", WithBaseURL("http://127.0.0.1:0"), WithRetryPolicy(RetryPolicy{}))
+	_, err := client.GetTranscript(context.Background(), "abc")
+	var networkErr *NetworkError
+	if !errors.As(err, &networkErr) {
+		t.Errorf("Expected a *NetworkError, got %T.", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "audio" {
+			t.Errorf("Expected the file content to be uploaded, got %s.", string(body))
+		}
+		w.Write([]byte(`{"upload_url": "https://cdn.assemblyai.com/upload/1"}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL))
+	uploadURL, err := client.Upload(context.Background(), strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if uploadURL != "https://cdn.assemblyai.com/upload/1" {
+		t.Errorf("Expected upload URL, got %s.", uploadURL)
+	}
+}
+
+func TestWaitForTranscript(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Write([]byte(`{"id": "abc", "status": "processing"}`))
+			return
+		}
+		w.Write([]byte(`{"id": "abc", "status": "completed", "text": "Hello."}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL))
+	transcript, err := client.WaitForTranscript(context.Background(), "abc", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 polls, got %d.", calls)
+	}
+	if *transcript.Text != "Hello." {
+		t.Errorf("Expected completed transcript text, got %s.", *transcript.Text)
+	}
+}
+
+func TestWaitForRedactedAudio(t *testing.T) {
+	calls := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redacted.mp3" {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Expected the download not to carry the token, got %s.", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte("beep"))
+			return
+		}
+		calls++
+		if calls < 2 {
+			w.Write([]byte(`{"status": "redacted_audio_processing"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "redacted_audio_ready", "redacted_audio_url": "` + server.URL + `/redacted.mp3"}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL))
+	redactedAudio, err := client.WaitForRedactedAudio(context.Background(), "abc", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 polls, got %d.", calls)
+	}
+	body, size, err := client.Download(context.Background(), redactedAudio.RedactedAudioURL)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	if string(data) != "beep" || size != 4 {
+		t.Errorf("Expected the redacted audio, got %q of size %d.", data, size)
+	}
+}
+
+func TestRealtime(t *testing.T) {
+	server := fakeapi.New("token")
+	defer server.Close()
+
+	_, err := New("invalid", WithBaseURL(server.URL)).StartRealtime(context.Background(), S.RealtimeParams{SampleRate: 16000})
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) || authErr.StatusCode != 4001 {
+		t.Errorf("Expected an *AuthenticationError for close code 4001, got %v", err)
+	}
+
+	client := New("token", WithBaseURL(server.URL))
+	session, err := client.StartRealtime(context.Background(), S.RealtimeParams{SampleRate: 16000, WordBoost: []string{"AssemblyAI"}})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer session.Close()
+	if session.ID == "" {
+		t.Error("Expected the session ID from SessionBegins.")
+	}
+	if err := session.Send(make([]byte, 3200)); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	message, err := session.Receive()
+	if err != nil || message.MessageType != "PartialTranscript" || message.Text != "Hello" || message.AudioEnd != 100 {
+		t.Errorf("Expected a partial transcript, got %+v %v", message, err)
+	}
+	session.Terminate()
+	types := []string{}
+	for {
+		message, err := session.Receive()
+		if message != nil {
+			types = append(types, message.MessageType)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Errorf("Expected io.EOF once terminated, got %v", err)
+			}
+			break
+		}
+	}
+	if strings.Join(types, ",") != "FinalTranscript,SessionTerminated" {
+		t.Errorf("Expected the final transcript before the session ends, got %v", types)
+	}
+}
+
+func TestListAllTranscripts(t *testing.T) {
+	server := fakeapi.New("token")
+	defer server.Close()
+	for _, id := range []string{"tr_1", "tr_2", "tr_3", "tr_4", "tr_5"} {
+		server.AddTranscript(id, nil)
+	}
+
+	client := New("token", WithBaseURL(server.URL))
+	ids := []string{}
+	err := client.ListAllTranscripts(context.Background(), S.TranscriptListParams{Limit: 2}, func(transcript S.TranscriptListItem) bool {
+		ids = append(ids, transcript.ID)
+		return transcript.ID != "tr_2"
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if strings.Join(ids, ",") != "tr_5,tr_4,tr_3,tr_2" {
+		t.Errorf("Expected to walk the pages newest first until tr_2, got %v.", ids)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIError is returned when the API answers with a non-2xx status code. It is
+// always wrapped in one of the more specific error types below, so callers can
+// either match a category with errors.As or get at the details through
+// *APIError.
+type APIError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	Method     string
+	Path       string
+	// RetryAfter is how long the API asked us to wait before retrying, or
+	// zero when it didn't say.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s %s: %d %s (request ID %s)", e.Method, e.Path, e.StatusCode, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// AuthenticationError is returned for 401 and 403 responses.
+type AuthenticationError struct{ *APIError }
+
+func (e *AuthenticationError) Unwrap() error { return e.APIError }
+
+// InvalidRequestError is returned for 4xx responses caused by the request
+// itself, e.g. unknown parameters or transcript IDs.
+type InvalidRequestError struct{ *APIError }
+
+func (e *InvalidRequestError) Unwrap() error { return e.APIError }
+
+// RateLimitError is returned for 429 responses.
+type RateLimitError struct{ *APIError }
+
+func (e *RateLimitError) Unwrap() error { return e.APIError }
+
+// ServerError is returned for 5xx responses.
+type ServerError struct{ *APIError }
+
+func (e *ServerError) Unwrap() error { return e.APIError }
+
+// NetworkError is returned when no response was received at all.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return "could not reach the AssemblyAI API: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+func newAPIError(resp *http.Response, body []byte) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Method:     resp.Request.Method,
+		Path:       resp.Request.URL.Path,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		apiErr.Message = payload.Error
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &AuthenticationError{apiErr}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{apiErr}
+	case resp.StatusCode >= 500:
+		return &ServerError{apiErr}
+	default:
+		return &InvalidRequestError{apiErr}
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"context"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+// LemurTask runs a free-form prompt over the transcripts.
+func (c *Client) LemurTask(ctx context.Context, params S.LemurParams) (*S.LemurResponse, error) {
+	return c.lemur(ctx, "task", params)
+}
+
+// LemurSummary summarizes the transcripts.
+func (c *Client) LemurSummary(ctx context.Context, params S.LemurParams) (*S.LemurResponse, error) {
+	return c.lemur(ctx, "summary", params)
+}
+
+// LemurQuestionAnswer answers each of params.Questions. The response is a
+// list of answers.
+func (c *Client) LemurQuestionAnswer(ctx context.Context, params S.LemurParams) (*S.LemurResponse, error) {
+	return c.lemur(ctx, "question-answer", params)
+}
+
+// LemurActionItems lists the action items of the transcripts.
+func (c *Client) LemurActionItems(ctx context.Context, params S.LemurParams) (*S.LemurResponse, error) {
+	return c.lemur(ctx, "action-items", params)
+}
+
+// lemur sends params to a LeMUR endpoint. The requests are billed, so they
+// are only retried when the API rejected them.
+func (c *Client) lemur(ctx context.Context, endpoint string, params S.LemurParams) (*S.LemurResponse, error) {
+	var response S.LemurResponse
+	data, err := c.callJSON(ctx, "POST", "/lemur/v3/generate/"+endpoint, params, &response)
+	if err != nil {
+		return nil, err
+	}
+	response.Raw = data
+	if answers, ok := response.Response.([]interface{}); ok {
+		parsed := []S.LemurAnswer{}
+		for _, answer := range answers {
+			fields, _ := answer.(map[string]interface{})
+			question, _ := fields["question"].(string)
+			text, _ := fields["answer"].(string)
+			parsed = append(parsed, S.LemurAnswer{Question: question, Answer: text})
+		}
+		response.Response = parsed
+	}
+	return &response, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gorilla/websocket"
+)
+
+// realtimePath is the websocket endpoint of real-time transcription.
+const realtimePath = "/v2/realtime/ws"
+
+// terminateTimeout bounds waiting for the last transcripts once a session was
+// asked to terminate.
+var terminateTimeout = 15 * time.Second
+
+// RealtimeSession is an open real-time transcription session. Audio is sent
+// with Send from one goroutine while transcripts are read with Receive from
+// another.
+type RealtimeSession struct {
+	conn *websocket.Conn
+	// ID and ExpiresAt come from the SessionBegins message.
+	ID        string
+	ExpiresAt string
+}
+
+// StartRealtime opens a real-time session and waits for the API to begin it.
+func (c *Client) StartRealtime(ctx context.Context, params S.RealtimeParams) (*RealtimeSession, error) {
+	endpoint, err := c.realtimeURL(params)
+	if err != nil {
+		return nil, err
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: 45 * time.Second}
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		dialer.NetDialContext = transport.DialContext
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+	header := http.Header{}
+	header.Set("Authorization", c.token)
+	if c.userAgent != "" {
+		header.Set("User-Agent", c.userAgent)
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			if resp.Request == nil {
+				resp.Request = &http.Request{Method: "GET", URL: &url.URL{Path: realtimePath}}
+			}
+			return nil, newAPIError(resp, data)
+		}
+		return nil, &NetworkError{Err: err}
+	}
+
+	session := &RealtimeSession{conn: conn}
+	message, err := session.Receive()
+	if err == nil && message.MessageType != "SessionBegins" {
+		err = fmt.Errorf("expected the session to begin, got %s", message.MessageType)
+	}
+	if err != nil {
+		conn.Close()
+		if errors.Is(err, io.EOF) {
+			err = errors.New("the session was terminated before it began")
+		}
+		return nil, err
+	}
+	session.ID = message.SessionID
+	session.ExpiresAt = message.ExpiresAt
+	return session, nil
+}
+
+// CreateRealtimeToken returns a temporary token that can start real-time
+// sessions for expiresIn seconds, e.g. from a browser, without exposing the
+// API key.
+func (c *Client) CreateRealtimeToken(ctx context.Context, expiresIn int) (*S.RealtimeToken, error) {
+	var token S.RealtimeToken
+	if _, err := c.callJSON(ctx, "POST", "/v2/realtime/token", map[string]int{"expires_in": expiresIn}, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (c *Client) realtimeURL(params S.RealtimeParams) (string, error) {
+	endpoint, err := url.Parse(c.baseURL + realtimePath)
+	if err != nil {
+		return "", err
+	}
+	switch endpoint.Scheme {
+	case "https":
+		endpoint.Scheme = "wss"
+	case "http":
+		endpoint.Scheme = "ws"
+	}
+	query := url.Values{}
+	query.Set("sample_rate", strconv.Itoa(params.SampleRate))
+	if len(params.WordBoost) > 0 {
+		wordBoost, err := json.Marshal(params.WordBoost)
+		if err != nil {
+			return "", err
+		}
+		query.Set("word_boost", string(wordBoost))
+	}
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// Send streams a chunk of 16-bit mono PCM audio. Chunks should hold between
+// 100ms and 2s of audio.
+func (s *RealtimeSession) Send(audio []byte) error {
+	message := map[string]string{"audio_data": base64.StdEncoding.EncodeToString(audio)}
+	if err := s.conn.WriteJSON(message); err != nil {
+		return &NetworkError{Err: err}
+	}
+	return nil
+}
+
+// Terminate asks the API to end the session. Receive keeps returning the last
+// transcripts until the API confirms it.
+func (s *RealtimeSession) Terminate() error {
+	if err := s.conn.WriteJSON(map[string]bool{"terminate_session": true}); err != nil {
+		return &NetworkError{Err: err}
+	}
+	s.conn.SetReadDeadline(time.Now().Add(terminateTimeout))
+	return nil
+}
+
+// Receive returns the next message of the session, or io.EOF once the
+// session is terminated. Errors reported by the API are returned with the same
+// types as the other endpoints.
+func (s *RealtimeSession) Receive() (*S.RealtimeMessage, error) {
+	_, data, err := s.conn.ReadMessage()
+	if err != nil {
+		return nil, realtimeError(err)
+	}
+	var message S.RealtimeMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+	message.Raw = data
+	if message.Error != "" {
+		return nil, &InvalidRequestError{&APIError{StatusCode: http.StatusBadRequest, Message: message.Error, Method: "GET", Path: realtimePath}}
+	}
+	if message.MessageType == "SessionTerminated" {
+		return &message, io.EOF
+	}
+	return &message, nil
+}
+
+// Close closes the connection without waiting for the session to terminate.
+func (s *RealtimeSession) Close() error {
+	return s.conn.Close()
+}
+
+// realtimeError turns the close codes of the real-time API into the error
+// types of the other endpoints, with the close code as status code.
+func realtimeError(err error) error {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return &NetworkError{Err: err}
+	}
+	switch closeErr.Code {
+	case websocket.CloseNormalClosure:
+		return io.EOF
+	case websocket.CloseAbnormalClosure:
+		return &NetworkError{Err: err}
+	}
+	apiErr := &APIError{StatusCode: closeErr.Code, Message: closeErr.Text, Method: "GET", Path: realtimePath}
+	if apiErr.Message == "" {
+		apiErr.Message = "Connection closed with code " + strconv.Itoa(closeErr.Code)
+	}
+	switch {
+	case closeErr.Code == 4001:
+		return &AuthenticationError{apiErr}
+	case closeErr.Code == 4029:
+		return &RateLimitError{apiErr}
+	case closeErr.Code == websocket.CloseInternalServerErr || closeErr.Code >= 4500:
+		return &ServerError{apiErr}
+	default:
+		return &InvalidRequestError{apiErr}
+	}
+}
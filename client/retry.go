@@ -0,0 +1,153 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a network error, a 429 or
+// a 5xx response are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero
+	// disables retries.
+	MaxRetries int
+	// Timeout bounds the time spent retrying a request: no retry starts
+	// later than Timeout after the first attempt. It doesn't cut a running
+	// attempt short, so long uploads aren't aborted. Zero means no limit
+	// besides the request context.
+	Timeout time.Duration
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// attempts. A Retry-After header sent by the API takes precedence.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Timeout:    2 * time.Minute,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+// WithRetryPolicy replaces the default retry policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+type retryMode int
+
+const (
+	// retryTransient retries network errors, 429 and 5xx responses. It is
+	// used for requests that are safe to repeat.
+	retryTransient retryMode = iota
+	// retryRejected only retries failures the API can't have acted on: 429
+	// responses and connections that were never established.
+	retryRejected
+)
+
+// replayable is a body every attempt can read from the start on its own, such
+// as *io.SectionReader, *bytes.Reader or *strings.Reader.
+type replayable interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// do sends a request, retrying it according to the client's retry policy.
+// A non-nil body is only retried when it's replayable. Each attempt then reads
+// it through its own io.SectionReader: net/http may still be reading the body
+// of a failed attempt, so attempts never share a read position.
+func (c *Client) do(ctx context.Context, method string, path string, body io.Reader, mode retryMode) ([]byte, error) {
+	var deadline time.Time
+	if c.retry.Timeout > 0 {
+		deadline = time.Now().Add(c.retry.Timeout)
+	}
+	replay, isReplayable := body.(replayable)
+
+	for attempt := 0; ; attempt++ {
+		attemptBody := body
+		if isReplayable {
+			attemptBody = io.NewSectionReader(replay, 0, replay.Size())
+		}
+		data, err := c.send(ctx, method, path, attemptBody)
+		if err == nil || attempt >= c.retry.MaxRetries || !shouldRetry(err, mode) {
+			return data, err
+		}
+		if body != nil && !isReplayable {
+			return data, err
+		}
+
+		wait := c.retry.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		if !deadline.IsZero() && time.Now().Add(wait).After(deadline) {
+			return data, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns a random duration between MinBackoff and the exponentially
+// growing upper bound for the given attempt ("full jitter").
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	upper := p.MinBackoff << uint(attempt)
+	if upper <= 0 || (p.MaxBackoff > 0 && upper > p.MaxBackoff) {
+		upper = p.MaxBackoff
+	}
+	if upper <= p.MinBackoff {
+		return p.MinBackoff
+	}
+	return p.MinBackoff + time.Duration(rand.Int63n(int64(upper-p.MinBackoff)))
+}
+
+func shouldRetry(err error, mode retryMode) bool {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return true
+	}
+	var networkErr *NetworkError
+	if errors.As(err, &networkErr) {
+		if mode == retryTransient {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	var serverErr *ServerError
+	return mode == retryTransient && errors.As(err, &serverErr)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+var fastRetries = RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// flakyServer fails the first failures requests with status and then
+// answers with body.
+func flakyServer(failures int32, status int, body string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	return server, &calls
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	server, calls := flakyServer(2, http.StatusBadGateway, `{"id": "abc", "status": "completed"}`)
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	if _, err := client.GetTranscript(context.Background(), "abc"); err != nil {
+		t.Fatalf("Expected the request to succeed after retries, got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d.", *calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, calls := flakyServer(10, http.StatusServiceUnavailable, `{}`)
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	_, err := client.GetTranscript(context.Background(), "abc")
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("Expected a *ServerError, got %v", err)
+	}
+	if *calls != 4 {
+		t.Errorf("Expected 4 attempts, got %d.", *calls)
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	server, calls := flakyServer(10, http.StatusInternalServerError, `{}`)
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 10, Timeout: 100 * time.Millisecond, MinBackoff: 60 * time.Millisecond, MaxBackoff: 60 * time.Millisecond}
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(policy))
+	if _, err := client.GetTranscript(context.Background(), "abc"); err == nil {
+		t.Fatal("Expected an error once the retry timeout is reached")
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 attempts within the retry timeout, got %d.", *calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+	var first time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if time.Since(first) < time.Second {
+			t.Errorf("Expected Retry-After to be honoured, retried after %s.", time.Since(first))
+		}
+		w.Write([]byte(`{"id": "abc", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	if _, err := client.SubmitTranscript(context.Background(), S.TranscribeParams{AudioURL: "https://example.com/a.mp3"}); err != nil {
+		t.Fatalf("Expected the rate limited submission to be retried, got %v", err)
+	}
+}
+
+func TestSubmitNotRetriedOnServerError(t *testing.T) {
+	server, calls := flakyServer(1, http.StatusInternalServerError, `{"id": "abc", "status": "queued"}`)
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	if _, err := client.SubmitTranscript(context.Background(), S.TranscribeParams{AudioURL: "https://example.com/a.mp3"}); err == nil {
+		t.Fatal("Expected the submission to fail without retrying")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected a single attempt, got %d.", *calls)
+	}
+}
+
+func TestUploadRetryRewindsBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "audio" {
+			t.Errorf("Expected the whole file on every attempt, got %q.", string(body))
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"upload_url": "https://cdn.assemblyai.com/upload/1"}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	if _, err := client.Upload(context.Background(), strings.NewReader("audio")); err != nil {
+		t.Fatalf("Expected the upload to succeed after a retry, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d.", calls)
+	}
+}
+
+func TestUploadRetryReadsEachAttemptFromTheStart(t *testing.T) {
+	audio := strings.Repeat("audio", 200000)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// Fail before the body is read, while the client may still send it.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != audio || r.ContentLength != int64(len(audio)) {
+			t.Errorf("Expected the whole file with its length, got %d bytes and a length of %d.", len(body), r.ContentLength)
+		}
+		w.Write([]byte(`{"upload_url": "https://cdn.assemblyai.com/upload/1"}`))
+	}))
+	defer server.Close()
+
+	client := New("token", WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	file := io.NewSectionReader(strings.NewReader(audio), 0, int64(len(audio)))
+	if _, err := client.Upload(context.Background(), file); err != nil {
+		t.Fatalf("Expected the upload to succeed after a retry, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d.", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait := parseRetryAfter("3"); wait != 3*time.Second {
+		t.Errorf("Expected 3s, got %s.", wait)
+	}
+	if wait := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); wait <= 0 || wait > time.Minute {
+		t.Errorf("Expected a wait of up to a minute, got %s.", wait)
+	}
+	if wait := parseRetryAfter("soon"); wait != 0 {
+		t.Errorf("Expected no wait for an invalid header, got %s.", wait)
+	}
+}
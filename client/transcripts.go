@@ -0,0 +1,216 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+// GetAccount returns the account the client's token belongs to.
+func (c *Client) GetAccount(ctx context.Context) (*S.Account, error) {
+	var account S.Account
+	if _, err := c.callJSON(ctx, "GET", "/v2/account", nil, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Upload streams r to the API and returns the URL to transcribe it from.
+// Uploads are retried like GET requests when r implements io.ReaderAt and
+// Size, as *io.SectionReader does.
+func (c *Client) Upload(ctx context.Context, r io.Reader) (string, error) {
+	data, err := c.do(ctx, "POST", "/v2/upload", r, retryTransient)
+	if err != nil {
+		return "", err
+	}
+	var upload S.UploadResponse
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return "", err
+	}
+	return upload.UploadURL, nil
+}
+
+// SubmitTranscript queues params.AudioURL for transcription.
+func (c *Client) SubmitTranscript(ctx context.Context, params S.TranscribeParams) (*S.TranscriptResponse, error) {
+	var transcript S.TranscriptResponse
+	data, err := c.callJSON(ctx, "POST", "/v2/transcript", params, &transcript)
+	if err != nil {
+		return nil, err
+	}
+	transcript.Raw = data
+	return &transcript, nil
+}
+
+// GetTranscript fetches the current state of a transcript.
+func (c *Client) GetTranscript(ctx context.Context, id string) (*S.TranscriptResponse, error) {
+	var transcript S.TranscriptResponse
+	data, err := c.callJSON(ctx, "GET", "/v2/transcript/"+id, nil, &transcript)
+	if err != nil {
+		return nil, err
+	}
+	transcript.Raw = data
+	return &transcript, nil
+}
+
+// WaitForTranscript polls a transcript every interval until it is either
+// completed or errored and returns it. Callers check Status to tell the two
+// apart.
+func (c *Client) WaitForTranscript(ctx context.Context, id string, interval time.Duration) (*S.TranscriptResponse, error) {
+	for {
+		transcript, err := c.GetTranscript(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if transcript.Status != nil && (*transcript.Status == "completed" || *transcript.Status == "error") {
+			return transcript, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ListTranscripts returns a single page of transcripts, newest first.
+func (c *Client) ListTranscripts(ctx context.Context, params S.TranscriptListParams) (*S.TranscriptList, error) {
+	query := url.Values{}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Status != "" {
+		query.Set("status", params.Status)
+	}
+	if params.CreatedOn != "" {
+		query.Set("created_on", params.CreatedOn)
+	}
+	if params.BeforeID != "" {
+		query.Set("before_id", params.BeforeID)
+	}
+	if params.AfterID != "" {
+		query.Set("after_id", params.AfterID)
+	}
+	if params.ThrottledOnly {
+		query.Set("throttled_only", "true")
+	}
+	path := "/v2/transcript"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var list S.TranscriptList
+	if _, err := c.callJSON(ctx, "GET", path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListAllTranscripts walks the transcript listing from newest to oldest,
+// following the pagination links, and calls fn for every transcript until fn
+// returns false or there are no more pages.
+func (c *Client) ListAllTranscripts(ctx context.Context, params S.TranscriptListParams, fn func(S.TranscriptListItem) bool) error {
+	for {
+		list, err := c.ListTranscripts(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, transcript := range list.Transcripts {
+			if !fn(transcript) {
+				return nil
+			}
+		}
+		if list.PageDetails.PrevURL == nil || len(list.Transcripts) == 0 {
+			return nil
+		}
+		prevURL, err := url.Parse(*list.PageDetails.PrevURL)
+		if err != nil {
+			return err
+		}
+		beforeID := prevURL.Query().Get("before_id")
+		if beforeID == "" {
+			beforeID = list.Transcripts[len(list.Transcripts)-1].ID
+		}
+		params.BeforeID = beforeID
+	}
+}
+
+// SearchWords returns where each of words occurs in a completed transcript.
+// A word can be a phrase of several words.
+func (c *Client) SearchWords(ctx context.Context, id string, words []string) (*S.WordSearchResponse, error) {
+	query := url.Values{}
+	query.Set("words", strings.Join(words, ","))
+	var result S.WordSearchResponse
+	if _, err := c.callJSON(ctx, "GET", "/v2/transcript/"+id+"/word-search?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSentences returns a completed transcript split into sentences, with the
+// start, end and speaker of each.
+func (c *Client) GetSentences(ctx context.Context, id string) (*S.SentencesResponse, error) {
+	var sentences S.SentencesResponse
+	if _, err := c.callJSON(ctx, "GET", "/v2/transcript/"+id+"/sentences", nil, &sentences); err != nil {
+		return nil, err
+	}
+	return &sentences, nil
+}
+
+// GetParagraphs returns a completed transcript split into paragraphs, with the
+// start, end and speaker of each.
+func (c *Client) GetParagraphs(ctx context.Context, id string) (*S.ParagraphsResponse, error) {
+	var paragraphs S.ParagraphsResponse
+	if _, err := c.callJSON(ctx, "GET", "/v2/transcript/"+id+"/paragraphs", nil, &paragraphs); err != nil {
+		return nil, err
+	}
+	return &paragraphs, nil
+}
+
+// GetRedactedAudio returns the state of the PII-redacted audio of a completed
+// transcript submitted with redact_pii_audio.
+func (c *Client) GetRedactedAudio(ctx context.Context, id string) (*S.RedactedAudioResponse, error) {
+	var redactedAudio S.RedactedAudioResponse
+	if _, err := c.callJSON(ctx, "GET", "/v2/transcript/"+id+"/redacted-audio", nil, &redactedAudio); err != nil {
+		return nil, err
+	}
+	return &redactedAudio, nil
+}
+
+// WaitForRedactedAudio polls the redacted audio of a transcript every interval
+// until it is ready and returns it.
+func (c *Client) WaitForRedactedAudio(ctx context.Context, id string, interval time.Duration) (*S.RedactedAudioResponse, error) {
+	for {
+		redactedAudio, err := c.GetRedactedAudio(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if redactedAudio.Status == "redacted_audio_ready" {
+			return redactedAudio, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// DeleteTranscript deletes the transcript's text and audio URL. The API keeps
+// a record of it with the content replaced.
+func (c *Client) DeleteTranscript(ctx context.Context, id string) (*S.TranscriptResponse, error) {
+	var transcript S.TranscriptResponse
+	data, err := c.callJSON(ctx, "DELETE", "/v2/transcript/"+id, nil, &transcript)
+	if err != nil {
+		return nil, err
+	}
+	transcript.Raw = data
+	return &transcript, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportOptions configures the *http.Client built by NewHTTPClient. Zero
+// values keep Go's defaults.
+type TransportOptions struct {
+	// Proxy is the URL of an HTTP(S) proxy. When empty, the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables are used.
+	Proxy string
+	// CABundle is a PEM file with certificate authorities to trust in
+	// addition to the system ones.
+	CABundle string
+	// ClientCert and ClientKey are PEM files presented for mutual TLS. The
+	// key may be left empty when it is bundled in the certificate file.
+	ClientCert string
+	ClientKey  string
+	// ConnectTimeout bounds establishing a connection, TLS handshake
+	// included.
+	ConnectTimeout time.Duration
+	// ReadTimeout bounds waiting for the response headers once the request
+	// has been sent.
+	ReadTimeout time.Duration
+}
+
+// NewHTTPClient builds an *http.Client honouring opts, to be passed to
+// WithHTTPClient or used for requests outside the API.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.ConnectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: opts.ConnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	}
+	transport.ResponseHeaderTimeout = opts.ReadTimeout
+
+	if opts.CABundle != "" || opts.ClientCert != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if opts.CABundle != "" {
+			pem, err := os.ReadFile(opts.CABundle)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("no certificates found in CA bundle " + opts.CABundle)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if opts.ClientCert != "" {
+			key := opts.ClientKey
+			if key == "" {
+				key = opts.ClientCert
+			}
+			cert, err := tls.LoadX509KeyPair(opts.ClientCert, key)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHTTPClient(TransportOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	client := New("token", WithBaseURL("http://api.example.com"), WithHTTPClient(httpClient))
+	if _, err := client.GetAccount(context.Background()); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if proxied != "http://api.example.com/v2/account" {
+		t.Errorf("Expected the request to go through the proxy, got %q.", proxied)
+	}
+}
+
+func TestHTTPClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted := New("token", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}))
+	if _, err := untrusted.GetAccount(context.Background()); err == nil {
+		t.Fatal("Expected the self-signed certificate to be rejected without the CA bundle")
+	}
+
+	httpClient, err := NewHTTPClient(TransportOptions{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	trusted := New("token", WithBaseURL(server.URL), WithHTTPClient(httpClient))
+	if _, err := trusted.GetAccount(context.Background()); err != nil {
+		t.Fatalf("Expected the CA bundle to be trusted, got %v", err)
+	}
+}
+
+func TestHTTPClientReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	httpClient, err := NewHTTPClient(TransportOptions{ReadTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	client := New("token", WithBaseURL(server.URL), WithHTTPClient(httpClient), WithRetryPolicy(RetryPolicy{}))
+	_, err = client.GetAccount(context.Background())
+	if _, ok := err.(*NetworkError); !ok {
+		t.Errorf("Expected a *NetworkError after the read timeout, got %v.", err)
+	}
+}
+
+func TestHTTPClientInvalidOptions(t *testing.T) {
+	if _, err := NewHTTPClient(TransportOptions{Proxy: "::"}); err == nil {
+		t.Error("Expected an invalid proxy URL to be rejected")
+	}
+	if _, err := NewHTTPClient(TransportOptions{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected a missing CA bundle to be rejected")
+	}
+}
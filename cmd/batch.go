@@ -0,0 +1,51 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Manage batch transcriptions",
+	Long: `Batches are started by passing several files, directories, patterns or a
+manifest to transcribe. Their progress is saved, so an interrupted batch can
+be resumed without uploading or submitting its files again.`,
+}
+
+var batchResumeCmd = &cobra.Command{
+	Use:   "resume <job>",
+	Short: "Resume an interrupted batch",
+	Long: `Resume a batch where it stopped. Finished files are skipped, submitted
+transcripts are polled again and the other files start over.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		webhookAuthHeaderValue, _ := cmd.Flags().GetString("webhook_auth_header_value")
+		client := U.Authenticate()
+		U.ResumeBatch(client, args[0], webhookAuthHeaderValue)
+	},
+}
+
+var batchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List batch jobs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		U.ListBatches()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.AddCommand(batchResumeCmd)
+	batchCmd.AddCommand(batchListCmd)
+	batchResumeCmd.Flags().String("webhook_auth_header_value", "", "The value of the webhook header of the batch, which isn't saved in its journal.")
+	for _, command := range []*cobra.Command{batchResumeCmd, batchListCmd} {
+		command.Flags().Bool("test", false, "Flag for test executing purpose")
+		command.Flags().MarkHidden("test")
+	}
+}
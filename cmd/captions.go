@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addCaptionFlags registers the flags shared by every command that writes SRT
+// or WebVTT files.
+func addCaptionFlags(cmd *cobra.Command) {
+	defaults := U.DefaultCaptionOptions
+	cmd.PersistentFlags().Int("chars-per-caption", defaults.CharsPerLine, "The maximum number of characters on each caption line. 0 disables line wrapping.")
+	cmd.PersistentFlags().Int("lines-per-caption", defaults.LinesPerCaption, "The maximum number of lines in a caption. 0 allows any number of lines.")
+	cmd.PersistentFlags().Duration("min-caption-duration", defaults.MinDuration, "The minimum time a caption stays on screen.")
+	cmd.PersistentFlags().Duration("max-caption-duration", defaults.MaxDuration, "The maximum time a caption stays on screen. 0 disables the limit.")
+	cmd.PersistentFlags().Duration("caption-gap", defaults.MaxGap, "Start a new caption after a pause longer than this. 0 only breaks on punctuation.")
+}
+
+// getCaptionOptions reads the flags added by addCaptionFlags and exits if
+// they can't produce captions.
+func getCaptionOptions(cmd *cobra.Command) S.CaptionOptions {
+	var opts S.CaptionOptions
+	opts.CharsPerLine, _ = cmd.Flags().GetInt("chars-per-caption")
+	opts.LinesPerCaption, _ = cmd.Flags().GetInt("lines-per-caption")
+	opts.MinDuration, _ = cmd.Flags().GetDuration("min-caption-duration")
+	opts.MaxDuration, _ = cmd.Flags().GetDuration("max-caption-duration")
+	opts.MaxGap, _ = cmd.Flags().GetDuration("caption-gap")
+	if err := U.ValidateCaptionOptions(opts); err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Invalid caption options: " + err.Error() + ".",
+		}
+		U.PrintError(printErrorProps)
+	}
+	return opts
+}
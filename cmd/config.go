@@ -5,16 +5,26 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	U "github.com/AssemblyAI/assemblyai-cli/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // configCmd represents the config command
 var configCmd = &cobra.Command{
 	Use:   "config [token]",
 	Short: "Authenticate the CLI",
-	Long:  `This command will validate your account and store your token safely, later to be used when transcribing files.`,
+	Long: `This command will validate your account and store your token safely, later to be used when transcribing files.
+The token is stored in the system keyring when there is one, see --token-storage.
+
+Use --profile to store the token in a named profile instead, e.g. for another
+account or environment. --base-url and --default are stored in the profile too.`,
+	Example: `  assemblyai config [token]
+  assemblyai config --profile staging [token] --base-url https://staging.example.com --default speaker_labels=true`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		argsArray := cmd.Flags().Args()
@@ -26,9 +36,26 @@ var configCmd = &cobra.Command{
 			fmt.Println("Too many arguments. Please provide a single token.")
 			return
 		}
+		profile := U.DefaultProfile
+		if U.ProfileOverride != "" {
+			profile = U.ProfileOverride
+		}
+		defaults := getTranscribeDefaults(cmd)
+		storage, _ := cmd.Flags().GetString("token-storage")
+		if !cmd.Flags().Changed("token-storage") && os.Getenv("ASSEMBLYAI_TOKEN_STORAGE") != "" {
+			storage = os.Getenv("ASSEMBLYAI_TOKEN_STORAGE")
+		}
+		if !U.Contains(U.TokenStorages, storage) {
+			printErrorProps := S.PrintErrorProps{
+				Error:   fmt.Errorf("unknown token storage %q", storage),
+				Message: fmt.Sprintf("Invalid --token-storage %q. Valid values are %s.", storage, strings.Join(U.TokenStorages, ", ")),
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
 		U.Token = argsArray[0]
 
-		checkToken := U.CheckIfTokenValid()
+		checkToken := U.CheckIfTokenValid(U.NewClient(U.Token))
 		if !checkToken {
 			fmt.Println(U.INVALID_TOKEN)
 			return
@@ -39,20 +66,317 @@ var configCmd = &cobra.Command{
 		}
 
 		U.CreateConfigFile()
-		U.SetConfigFileValue("features.telemetry", "true")
-		U.SetConfigFileValue("config.token", U.Token)
+		if U.GetConfigFileValue("features.telemetry") == "" {
+			U.SetConfigFileValue("features.telemetry", "true")
+		}
+		if _, err := U.StoreToken(profile, U.Token, storage); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not store the token: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
 		U.SetConfigFileValue("config.distinct_id", U.DistinctId)
 		U.SetConfigFileValue("config.new", "false")
+		if cmd.Flags().Changed("base-url") {
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			U.SetProfileValue(profile, "api.base_url", baseURL)
+		}
+		for name, value := range defaults {
+			U.SetProfileValue(profile, "transcribe."+name, value)
+		}
 
 		U.TelemetryCaptureEvent("CLI configured", nil)
 
+		if profile != U.DefaultProfile {
+			fmt.Printf("You're now authenticated in the %s profile.\n", profile)
+			return
+		}
 		fmt.Println("You're now authenticated.")
 	},
 }
 
+// configProfilesCmd represents the config profiles command
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage configuration profiles",
+	Long: `Profiles hold a token, a base URL and default transcription settings, e.g. for
+different accounts or environments. Create them with assemblyai config --profile,
+and select one with --profile, the ASSEMBLYAI_PROFILE environment variable, or
+assemblyai config profiles use.`,
+}
+
+// configProfilesListCmd represents the config profiles list command
+var configProfilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configuration profiles",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		U.PrintProfiles(os.Stdout)
+	},
+}
+
+// configProfilesUseCmd represents the config profiles use command
+var configProfilesUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Use a profile when none is selected",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := U.UseProfile(args[0]); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not use the profile: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Printf("Now using the %s profile.\n", args[0])
+	},
+}
+
+// configProfilesRemoveCmd represents the config profiles remove command
+var configProfilesRemoveCmd = &cobra.Command{
+	Use:   "remove <profile>",
+	Short: "Remove a profile and its token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := U.RemoveProfile(args[0]); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not remove the profile: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Printf("Removed the %s profile.\n", args[0])
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:     "get <key>",
+	Short:   "Print the value of a setting",
+	Long:    `Print the value of a setting for the active profile, or its default when it isn't set.`,
+	Example: `  assemblyai config get api.read_timeout`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		U.CheckActiveProfile()
+		setting, err := U.FindSetting(args[0])
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Invalid setting: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Println(U.ResolveSetting(setting).Value)
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Long: `Validate and store a setting in the active profile. Settings marked as global
+are shared by every profile. Run assemblyai config list to see the settings.`,
+	Example: `  assemblyai config set features.telemetry false
+  assemblyai config set output.format srt
+  assemblyai config set transcribe.language_code es`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		U.CheckActiveProfile()
+		value, err := U.SetSetting(U.ActiveProfile(), args[0], args[1])
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Invalid setting: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Printf("Set %s to %s.\n", strings.ToLower(args[0]), value)
+	},
+}
+
+// configUnsetCmd represents the config unset command
+var configUnsetCmd = &cobra.Command{
+	Use:     "unset <key>",
+	Short:   "Reset a setting to its default",
+	Example: `  assemblyai config unset api.read_timeout`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		U.CheckActiveProfile()
+		removed, err := U.UnsetSetting(U.ActiveProfile(), args[0])
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Invalid setting: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		if !removed {
+			fmt.Printf("%s isn't set.\n", strings.ToLower(args[0]))
+			return
+		}
+		fmt.Printf("Unset %s.\n", strings.ToLower(args[0]))
+	},
+}
+
+// configListCmd represents the config list command
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the settings and their values",
+	Long: `List every setting with its value for the active profile, and where the value
+comes from: a flag, an environment variable, the project file, the config file,
+or the default.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.ConfigFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		U.CheckActiveProfile()
+		U.PrintSettings(os.Stdout, flags)
+	},
+}
+
+// configWhichCmd represents the config which command
+var configWhichCmd = &cobra.Command{
+	Use:   "which [key]",
+	Short: "Show the effective settings and where they come from",
+	Long: `Show the project file and the profile in use, then the effective value of every
+setting and where it comes from: a flag, an environment variable, the project
+file, the profile, the config file, or the default.
+
+Project files are .assemblyai.toml, .assemblyai.yaml or .assemblyai.yml files
+found in the current directory or one of its parents.`,
+	Example: `  assemblyai config which
+  assemblyai config which transcribe.language_code`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.ConfigFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		U.CheckActiveProfile()
+		if len(args) == 0 {
+			U.PrintWhich(os.Stdout, flags)
+			return
+		}
+		setting, err := U.FindSetting(args[0])
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Invalid setting: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		U.PrintSettingSource(os.Stdout, setting, flags)
+	},
+}
+
+// registerTranscribeSettings adds the flags of transcribe to the settings as
+// transcribe.[flag], the defaults of transcriptions.
+func registerTranscribeSettings(cmd *cobra.Command) {
+	cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		setting := S.ConfigSetting{Key: "transcribe." + flag.Name, Type: U.SettingString, Default: flag.DefValue, Description: flag.Usage}
+		switch flag.Value.Type() {
+		case "bool":
+			setting.Type = U.SettingBool
+		case "int":
+			setting.Type = U.SettingInt
+		case "duration":
+			setting.Type = U.SettingDuration
+		case "stringSlice", "stringArray":
+			setting.Default = strings.Trim(flag.DefValue, "[]")
+		}
+		U.RegisterSetting(setting)
+	})
+}
+
+// getTranscribeDefaults parses --default into transcription flag values,
+// keyed by flag name.
+func getTranscribeDefaults(cmd *cobra.Command) map[string]string {
+	values, _ := cmd.Flags().GetStringArray("default")
+	defaults := map[string]string{}
+	for _, value := range values {
+		name, value, _ := strings.Cut(value, "=")
+		name = strings.TrimPrefix(strings.TrimSpace(name), "--")
+		setting, err := U.FindSetting("transcribe." + name)
+		if err == nil {
+			value, err = U.ValidateSetting(setting, value)
+		}
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: fmt.Sprintf("Invalid --default %q: %s.", name+"="+value, err),
+			}
+			U.PrintError(printErrorProps)
+			return nil
+		}
+		defaults[name] = value
+	}
+	return defaults
+}
+
+// applyTranscribeDefaults sets the flags that weren't given to the defaults of
+// the active profile and the project file. They behave like the built-in
+// defaults, so they don't count as given.
+func applyTranscribeDefaults(cmd *cobra.Command) {
+	for name, value := range U.TranscribeDefaults() {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := setFlagDefault(flag, value); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: fmt.Sprintf("Invalid default %s = %q in %s.", name, value, transcribeDefaultSource(name)),
+			}
+			U.PrintError(printErrorProps)
+		}
+	}
+}
+
+// transcribeDefaultSource names where the default of a transcription flag
+// comes from: the project file, a profile or the config file.
+func transcribeDefaultSource(name string) string {
+	source := "the config file"
+	if setting, err := U.FindSetting("transcribe." + name); err == nil {
+		source = U.ResolveSetting(setting).Source
+	}
+	if source == "config file" || strings.HasSuffix(source, " profile") {
+		return "the " + source
+	}
+	return source
+}
+
 func init() {
+	configCmd.Flags().String("token-storage", U.TokenStorageAuto, "Where to store the token: "+strings.Join(U.TokenStorages, ", ")+". auto uses the keyring when there is one, then the encrypted file when ASSEMBLYAI_KEYRING_PASSWORD is set. Defaults to the ASSEMBLYAI_TOKEN_STORAGE environment variable.")
+	configCmd.Flags().StringArray("default", nil, "A default transcription setting stored in the profile, as flag=value. Repeat it to store several.")
 	configCmd.Flags().Bool("test", false, "Flag for test executing purpose")
 	configCmd.Flags().MarkHidden("test")
 
 	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configProfilesCmd)
+	configProfilesCmd.AddCommand(configProfilesListCmd)
+	configProfilesCmd.AddCommand(configProfilesUseCmd)
+	configProfilesCmd.AddCommand(configProfilesRemoveCmd)
+	configProfilesCmd.PersistentFlags().Bool("test", false, "Flag for test executing purpose")
+	configProfilesCmd.PersistentFlags().MarkHidden("test")
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configWhichCmd)
+	configListCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	configWhichCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	for _, subcommand := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configWhichCmd} {
+		subcommand.Flags().Bool("test", false, "Flag for test executing purpose")
+		subcommand.Flags().MarkHidden("test")
+	}
 }
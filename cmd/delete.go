@@ -0,0 +1,116 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete [transcription_id...]",
+	Short: "Delete transcriptions",
+	Long: `Delete the text and audio of transcriptions. IDs can be passed as arguments,
+read from a file with --file, or piped through stdin, e.g.
+assemblyai list --ids-only --status error | assemblyai delete --yes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.DeleteFlags
+		flags.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		flags.Yes, _ = cmd.Flags().GetBool("yes")
+
+		ids := cmd.Flags().Args()
+		file, _ := cmd.Flags().GetString("file")
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		readingStdin := file == "-" || (file == "" && len(ids) == 0 && olderThan == "" && !term.IsTerminal(int(os.Stdin.Fd())))
+		if file != "" || readingStdin {
+			input := os.Stdin
+			if !readingStdin {
+				var err error
+				input, err = os.Open(file)
+				if err != nil {
+					printErrorProps := S.PrintErrorProps{
+						Error:   err,
+						Message: "Error opening file",
+					}
+					U.PrintError(printErrorProps)
+					return
+				}
+				defer input.Close()
+			}
+			fileIds, err := U.ReadIDs(input)
+			if err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "Error reading transcript IDs",
+				}
+				U.PrintError(printErrorProps)
+				return
+			}
+			ids = append(ids, fileIds...)
+		}
+		if readingStdin && !flags.Yes && !flags.DryRun {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("Confirmation required"),
+				Message: "Can't ask for confirmation while reading IDs from stdin. Please pass --yes or --dry-run.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+
+		if len(ids) == 0 && olderThan == "" && !readingStdin {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("No transcription ID provided."),
+				Message: "You must provide transcription IDs or --older-than.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+
+		client := U.Authenticate()
+		if olderThan != "" {
+			age, err := U.ParseAge(olderThan)
+			if err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "Invalid --older-than. Please use a duration such as 30d, 1d12h, 12h or 90m.",
+				}
+				U.PrintError(printErrorProps)
+				return
+			}
+			listFlags := S.ListFlags{Before: time.Now().UTC().Add(-age)}
+			transcripts, err := U.FindTranscripts(client, S.TranscriptListParams{Limit: maxPageSize}, listFlags)
+			if err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "We couldn't list your transcripts.",
+				}
+				U.PrintError(printErrorProps)
+				return
+			}
+			for _, transcript := range transcripts {
+				ids = append(ids, transcript.ID)
+			}
+		}
+
+		U.DeleteTranscripts(client, ids, flags)
+	},
+}
+
+func init() {
+	deleteCmd.Flags().String("file", "", "Read transcript IDs from a file, one per line. Use - for stdin.")
+	deleteCmd.Flags().String("older-than", "", "Delete every transcript created longer ago than this, e.g. 30d, 1d12h or 12h.")
+	deleteCmd.Flags().Bool("dry-run", false, "Only print the transcripts that would be deleted.")
+	deleteCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+	deleteCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	deleteCmd.Flags().MarkHidden("test")
+
+	rootCmd.AddCommand(deleteCmd)
+}
@@ -31,29 +31,23 @@ var getCmd = &cobra.Command{
 		id := args[0]
 		flags.Poll, _ = cmd.Flags().GetBool("poll")
 		flags.Json, _ = cmd.Flags().GetBool("json")
-		flags.Srt, _ = cmd.Flags().GetBool("srt")
-
-		U.Token = U.GetStoredToken()
-		if U.Token == "" {
-			printErrorProps := S.PrintErrorProps{
-				Error:   errors.New("No token found."),
-				Message: "Please start by running \033[1m\033[34massemblyai config [token]\033[0m",
-			}
-			U.PrintError(printErrorProps)
+		flags.Outputs = getOutputs(cmd, flags.Json)
+		flags.Captions = getCaptionOptions(cmd)
+		flags.Segmentation = getSegmentation(cmd)
+		if !checkSegmentation(flags.Segmentation, outputFormats(flags.Outputs)) {
 			return
 		}
 
-		checkToken := U.CheckIfTokenValid()
-		if !checkToken {
-			printErrorProps := S.PrintErrorProps{
-				Error:   errors.New("Invalid token"),
-				Message: U.INVALID_TOKEN,
-			}
-			U.PrintError(printErrorProps)
+		// The cache is read without checking the token with the API, so
+		// cached transcripts are shown offline.
+		U.RequireToken()
+		flags.Refresh, _ = cmd.Flags().GetBool("refresh")
+		if !flags.Refresh && U.ShowCachedTranscript(id, flags) {
 			return
 		}
 
-		U.PollTranscription(id, flags)
+		client := U.Authenticate()
+		U.PollTranscription(client, id, flags)
 	},
 }
 
@@ -61,7 +55,10 @@ func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
 	getCmd.Flags().BoolP("poll", "p", true, "The CLI will poll the transcription until it's complete.")
+	getCmd.Flags().Bool("refresh", false, "Fetch the transcription from the API even if it's in the local history.")
 	getCmd.Flags().Bool("test", false, "Flag for test executing purpose")
-	getCmd.PersistentFlags().BoolP("srt", "", false, "Generate an SRT file for the audio file transcribed.")
+	addOutputFlags(getCmd, "o")
+	addCaptionFlags(getCmd)
+	addSegmentationFlags(getCmd)
 	getCmd.Flags().MarkHidden("test")
 }
@@ -0,0 +1,36 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the transcriptions of this machine",
+	Long: `Browse the transcriptions submitted or fetched from this machine, most recent
+first, with the file or URL they were created from. Completed transcriptions
+are kept, so get can show them without calling the API again.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.HistoryFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		flags.Limit, _ = cmd.Flags().GetInt("limit")
+		flags.Status, _ = cmd.Flags().GetString("status")
+		U.ShowHistory(flags)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	historyCmd.Flags().IntP("limit", "n", 20, "The maximum number of transcriptions to show. 0 shows them all.")
+	historyCmd.Flags().String("status", "", "Only show transcriptions with this status, e.g. completed or deleted.")
+	historyCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	historyCmd.Flags().MarkHidden("test")
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// lemurCmd represents the lemur command
+var lemurCmd = &cobra.Command{
+	Use:   "lemur",
+	Short: "Apply LLM tasks to your transcripts with LeMUR",
+	Long: `Run a prompt, a summary, questions or action items over one or more
+completed transcripts with LeMUR. Transcripts are given by ID, or with --batch
+to use every completed transcript of a batch job.`,
+}
+
+// lemurTaskCmd represents the lemur task command
+var lemurTaskCmd = &cobra.Command{
+	Use:     "task [transcript_id...]",
+	Short:   "Run a custom prompt over transcripts",
+	Example: `  assemblyai lemur task 6ngnb9pi9k-2d2e-4b17-8fb2-b9ba2ad62e3b --prompt "List the topics discussed."`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := getLemurParams(cmd, args)
+		prompt, _ := cmd.Flags().GetString("prompt")
+		promptFile, _ := cmd.Flags().GetString("prompt-file")
+		if promptFile != "" {
+			data, err := os.ReadFile(promptFile)
+			if err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "Error reading the prompt file",
+				}
+				U.PrintError(printErrorProps)
+				return
+			}
+			prompt = string(data)
+		}
+		params.Prompt = strings.TrimSpace(prompt)
+		if params.Prompt == "" {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("empty prompt"),
+				Message: "Please provide a prompt with --prompt or --prompt-file.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		runLemur(cmd, "task", params)
+	},
+}
+
+// lemurSummaryCmd represents the lemur summary command
+var lemurSummaryCmd = &cobra.Command{
+	Use:     "summary [transcript_id...]",
+	Short:   "Summarize transcripts",
+	Example: `  assemblyai lemur summary 6ngnb9pi9k-2d2e-4b17-8fb2-b9ba2ad62e3b --answer-format "TLDR"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := getLemurParams(cmd, args)
+		params.AnswerFormat, _ = cmd.Flags().GetString("answer-format")
+		runLemur(cmd, "summary", params)
+	},
+}
+
+// lemurQuestionAnswerCmd represents the lemur question-answer command
+var lemurQuestionAnswerCmd = &cobra.Command{
+	Use:   "question-answer [transcript_id...]",
+	Short: "Ask questions about transcripts",
+	Example: `  assemblyai lemur question-answer 6ngnb9pi9k-2d2e-4b17-8fb2-b9ba2ad62e3b --question "Who is the guest?"
+  assemblyai lemur question-answer --batch 1 --questions-file questions.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := getLemurParams(cmd, args)
+		questions, _ := cmd.Flags().GetStringArray("question")
+		questionsFile, _ := cmd.Flags().GetString("questions-file")
+		if questionsFile != "" {
+			file, err := os.Open(questionsFile)
+			if err == nil {
+				var fileQuestions []string
+				fileQuestions, err = U.ReadQuestions(file)
+				file.Close()
+				questions = append(questions, fileQuestions...)
+			}
+			if err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "Error reading the questions file",
+				}
+				U.PrintError(printErrorProps)
+				return
+			}
+		}
+		answerFormat, _ := cmd.Flags().GetString("answer-format")
+		for _, question := range questions {
+			if question = strings.TrimSpace(question); question != "" {
+				params.Questions = append(params.Questions, S.LemurQuestion{Question: question, AnswerFormat: answerFormat})
+			}
+		}
+		if len(params.Questions) == 0 {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("no questions"),
+				Message: "Please provide at least one question with --question or --questions-file.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		runLemur(cmd, "question-answer", params)
+	},
+}
+
+// lemurActionItemsCmd represents the lemur action-items command
+var lemurActionItemsCmd = &cobra.Command{
+	Use:     "action-items [transcript_id...]",
+	Short:   "List the action items of transcripts",
+	Example: `  assemblyai lemur action-items 6ngnb9pi9k-2d2e-4b17-8fb2-b9ba2ad62e3b`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := getLemurParams(cmd, args)
+		params.AnswerFormat, _ = cmd.Flags().GetString("answer-format")
+		runLemur(cmd, "action-items", params)
+	},
+}
+
+// getLemurParams reads the transcripts and options shared by the lemur
+// commands.
+func getLemurParams(cmd *cobra.Command, args []string) S.LemurParams {
+	var params S.LemurParams
+	batch, _ := cmd.Flags().GetString("batch")
+	ids, err := U.LemurTranscriptIDs(args, batch)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Please provide transcript IDs or a batch job with completed transcripts: " + err.Error() + ".",
+		}
+		U.PrintError(printErrorProps)
+	}
+	params.TranscriptIDs = ids
+	params.Context, _ = cmd.Flags().GetString("context")
+	params.FinalModel, _ = cmd.Flags().GetString("model")
+	params.MaxOutputSize, _ = cmd.Flags().GetInt("max-output-size")
+	if params.MaxOutputSize < 0 {
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New("negative max output size"),
+			Message: "--max-output-size must be positive.",
+		}
+		U.PrintError(printErrorProps)
+	}
+	if cmd.Flags().Changed("temperature") {
+		temperature, _ := cmd.Flags().GetFloat64("temperature")
+		if temperature < 0 || temperature > 1 {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("invalid temperature"),
+				Message: "--temperature must be between 0 and 1.",
+			}
+			U.PrintError(printErrorProps)
+		}
+		params.Temperature = &temperature
+	}
+	return params
+}
+
+func runLemur(cmd *cobra.Command, endpoint string, params S.LemurParams) {
+	var flags S.LemurFlags
+	flags.Json, _ = cmd.Flags().GetBool("json")
+
+	client := U.Authenticate()
+	U.RunLemur(client, endpoint, params, flags)
+}
+
+func init() {
+	rootCmd.AddCommand(lemurCmd)
+	lemurCmd.PersistentFlags().String("batch", "", "Use the completed transcripts of a batch job, in addition to the IDs given.")
+	lemurCmd.PersistentFlags().String("context", "", "Context about the transcripts, e.g. what kind of audio it is.")
+	lemurCmd.PersistentFlags().String("model", "", "The model that generates the response, e.g. default or basic. Defaults to the API's choice.")
+	lemurCmd.PersistentFlags().Int("max-output-size", 0, "The maximum number of tokens of the response. Defaults to the API's choice.")
+	lemurCmd.PersistentFlags().Float64("temperature", 0, "How creative the response is, from 0 to 1. Defaults to the API's choice.")
+	lemurCmd.PersistentFlags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	lemurCmd.PersistentFlags().Bool("test", false, "Flag for test executing purpose")
+	lemurCmd.PersistentFlags().MarkHidden("test")
+
+	lemurCmd.AddCommand(lemurTaskCmd)
+	lemurTaskCmd.Flags().StringP("prompt", "p", "", "The prompt to run over the transcripts.")
+	lemurTaskCmd.Flags().String("prompt-file", "", "A file holding the prompt to run over the transcripts.")
+	lemurTaskCmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file")
+
+	lemurCmd.AddCommand(lemurSummaryCmd)
+	lemurSummaryCmd.Flags().String("answer-format", "", "How the summary should be written, e.g. \"TLDR\" or \"bullet points\".")
+
+	lemurCmd.AddCommand(lemurQuestionAnswerCmd)
+	lemurQuestionAnswerCmd.Flags().StringArrayP("question", "q", nil, "A question to answer. Repeat the flag to ask several.")
+	lemurQuestionAnswerCmd.Flags().String("questions-file", "", "A file with one question per line. Lines starting with # are ignored.")
+	lemurQuestionAnswerCmd.Flags().String("answer-format", "", "How every answer should be written, e.g. \"short sentence\".")
+
+	lemurCmd.AddCommand(lemurActionItemsCmd)
+	lemurActionItemsCmd.Flags().String("answer-format", "", "How the action items should be written, e.g. \"bullet points\".")
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// maxPageSize is the largest page the transcript listing endpoint returns.
+const maxPageSize = 200
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your transcriptions",
+	Long:  `Browse the transcriptions created with your account, newest first.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var params S.TranscriptListParams
+		flags, ok := getListFlags(cmd)
+		if !ok {
+			return
+		}
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		flags.IdsOnly, _ = cmd.Flags().GetBool("ids-only")
+
+		params.Status, _ = cmd.Flags().GetString("status")
+		if params.Status != "" && !U.Contains(S.TranscriptStatuses, params.Status) {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("Invalid status"),
+				Message: fmt.Sprintf("Invalid status. Valid values are %s.", strings.Join(S.TranscriptStatuses, ", ")),
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		params.ThrottledOnly, _ = cmd.Flags().GetBool("throttled-only")
+		params.Limit = pageSize(flags)
+
+		client := U.Authenticate()
+		U.ListTranscripts(client, params, flags)
+	},
+}
+
+// getListFlags reads the --limit, --after and --before flags shared by the
+// commands walking the transcript listing.
+func getListFlags(cmd *cobra.Command) (S.ListFlags, bool) {
+	var flags S.ListFlags
+	flags.Limit, _ = cmd.Flags().GetInt("limit")
+	for _, name := range []string{"after", "before"} {
+		value, _ := cmd.Flags().GetString(name)
+		if value == "" {
+			continue
+		}
+		date, err := U.ParseDate(value)
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: fmt.Sprintf("Invalid --%s date. Please use YYYY-MM-DD or an RFC 3339 timestamp.", name),
+			}
+			U.PrintError(printErrorProps)
+			return flags, false
+		}
+		if name == "after" {
+			flags.After = date
+		} else {
+			flags.Before = date
+		}
+	}
+	return flags, true
+}
+
+// pageSize returns how many transcripts to request per page. Date filters are
+// applied locally, so they need full pages.
+func pageSize(flags S.ListFlags) int {
+	if flags.Limit <= 0 || flags.Limit > maxPageSize || !flags.After.IsZero() || !flags.Before.IsZero() {
+		return maxPageSize
+	}
+	return flags.Limit
+}
+
+func init() {
+	listCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	listCmd.Flags().Bool("ids-only", false, "Only print the transcript IDs, one per line.")
+	listCmd.Flags().IntP("limit", "n", 10, "The maximum number of transcripts to list. Use 0 to list all of them.")
+	listCmd.Flags().String("status", "", "Only list transcripts with this status: queued, processing, completed or error.")
+	listCmd.Flags().String("after", "", "Only list transcripts created after this date (YYYY-MM-DD or RFC 3339).")
+	listCmd.Flags().String("before", "", "Only list transcripts created before this date (YYYY-MM-DD or RFC 3339).")
+	listCmd.Flags().Bool("throttled-only", false, "Only list throttled transcripts.")
+	listCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	listCmd.Flags().MarkHidden("test")
+
+	rootCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlags registers --format and --output. shorthand is the short
+// form of --output, or "" when the command already uses -o for something else.
+func addOutputFlags(cmd *cobra.Command, shorthand string) {
+	cmd.PersistentFlags().StringSlice("format", nil, "Output formats, repeated or comma-separated: "+strings.Join(U.FormatNames(), ", ")+". Use format=path to write one to its own file.")
+	cmd.PersistentFlags().StringP("output", shorthand, "", "Where to write the output: a file, a directory for one [id].[extension] file per format, or - for stdout.")
+	cmd.PersistentFlags().BoolP("srt", "", false, "Generate an SRT file for the audio file transcribed.")
+	cmd.PersistentFlags().BoolP("vtt", "", false, "Generate a WebVTT file for the audio file transcribed.")
+}
+
+// getOutputs resolves the output flags into targets. -j is kept as a shortcut
+// for --format json, and --srt and --vtt for writing [id].srt and [id].vtt to
+// the current directory.
+func getOutputs(cmd *cobra.Command, json bool) []S.OutputTarget {
+	formats, _ := cmd.Flags().GetStringSlice("format")
+	output, _ := cmd.Flags().GetString("output")
+	if json && !U.Contains(formats, "json") {
+		formats = append(formats, "json")
+	}
+	if len(formats) == 0 {
+		formats = []string{U.DefaultFormat()}
+	}
+	if srt, _ := cmd.Flags().GetBool("srt"); srt {
+		formats = append(formats, "srt=.")
+	}
+	if vtt, _ := cmd.Flags().GetBool("vtt"); vtt {
+		formats = append(formats, "vtt=.")
+	}
+
+	targets, err := U.ParseOutputs(formats, output)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Invalid output options: " + err.Error() + ".",
+		}
+		U.PrintError(printErrorProps)
+	}
+	return targets
+}
+
+// addSegmentationFlags registers --sentences and --paragraphs, which show the
+// formatted transcript split by the API.
+func addSegmentationFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("sentences", false, "Show the transcript split into sentences by the API, with the start, end and speaker of each.")
+	cmd.PersistentFlags().Bool("paragraphs", false, "Show the transcript split into paragraphs by the API, with the start, end and speaker of each.")
+	cmd.MarkFlagsMutuallyExclusive("sentences", "paragraphs")
+}
+
+// getSegmentation returns "sentences", "paragraphs", or "" to split the
+// transcript locally.
+func getSegmentation(cmd *cobra.Command) string {
+	if sentences, _ := cmd.Flags().GetBool("sentences"); sentences {
+		return "sentences"
+	}
+	if paragraphs, _ := cmd.Flags().GetBool("paragraphs"); paragraphs {
+		return "paragraphs"
+	}
+	return ""
+}
+
+// checkSegmentation rejects --sentences and --paragraphs with other formats
+// than text, which don't show them.
+func checkSegmentation(segmentation string, formats []string) bool {
+	if segmentation == "" {
+		return true
+	}
+	for _, format := range formats {
+		if format != "text" {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("Invalid segmentation"),
+				Message: fmt.Sprintf("--%s only changes the text output, it can't be used with the %s format.", segmentation, format),
+			}
+			U.PrintError(printErrorProps)
+			return false
+		}
+	}
+	return true
+}
+
+// outputFormats returns the formats of targets.
+func outputFormats(targets []S.OutputTarget) []string {
+	formats := []string{}
+	for _, target := range targets {
+		formats = append(formats, target.Format)
+	}
+	return formats
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// presetCmd represents the preset command
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage reusable sets of transcription flags",
+	Long: `Presets are named sets of transcribe flags, applied with transcribe --preset.
+Flags given to transcribe take precedence over the preset.
+
+Presets are saved in the config file, or shared with a project in the presets
+table of its project file, see config which:
+
+  presets:
+    calls:
+      speaker_labels: true
+      redact_pii: true
+      redact_pii_policies: [person_name, phone_number]`,
+}
+
+// presetSaveCmd represents the preset save command
+var presetSaveCmd = &cobra.Command{
+	Use:     "save <name> [transcribe flags]",
+	Short:   "Save the given transcribe flags as a preset",
+	Example: `  assemblyai preset save calls -l -x -e -r -i person_name,phone_number`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := map[string]string{}
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			if transcribeCmd.PersistentFlags().Lookup(flag.Name) == nil {
+				return
+			}
+			value := flag.Value.String()
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				value = strings.Join(slice.GetSlice(), ",")
+			}
+			flags[flag.Name] = value
+		})
+		if len(flags) == 0 {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("no flags"),
+				Message: "Please provide the transcribe flags to save, e.g. assemblyai preset save calls --speaker_labels.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		if err := U.SavePreset(args[0], flags); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not save the preset: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Printf("Saved the %s preset. Use it with assemblyai transcribe --preset %s.\n", args[0], args[0])
+	},
+}
+
+// presetListCmd represents the preset list command
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the presets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.PresetFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		U.PrintPresets(os.Stdout, flags)
+	},
+}
+
+// presetShowCmd represents the preset show command
+var presetShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the flags of a preset",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.PresetFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		preset := getPreset(args[0])
+		U.PrintPreset(os.Stdout, preset, flags)
+	},
+}
+
+// presetDeleteCmd represents the preset delete command
+var presetDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a preset",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := U.DeletePreset(args[0]); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not delete the preset: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		fmt.Printf("Deleted the %s preset.\n", args[0])
+	},
+}
+
+func getPreset(name string) S.Preset {
+	preset, err := U.GetPreset(name)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Invalid preset: " + err.Error() + ".",
+		}
+		U.PrintError(printErrorProps)
+	}
+	return preset
+}
+
+// applyPreset sets the flags that weren't given to the values of --preset.
+func applyPreset(cmd *cobra.Command) {
+	name, _ := cmd.Flags().GetString("preset")
+	if name == "" {
+		return
+	}
+	preset := getPreset(name)
+	for _, flagName := range U.PresetFlagNames(preset) {
+		value := preset.Flags[flagName]
+		flag := cmd.Flags().Lookup(flagName)
+		err := fmt.Errorf("transcribe has no --%s flag", flagName)
+		if flag != nil && flag.Name != "preset" {
+			if flag.Changed {
+				continue
+			}
+			err = setFlagDefault(flag, value)
+		}
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: fmt.Sprintf("Invalid %s = %q in the %s preset: %s.", flagName, value, preset.Name, err),
+			}
+			U.PrintError(printErrorProps)
+		}
+	}
+}
+
+// setFlagDefault replaces the value of a flag without marking it as given.
+func setFlagDefault(flag *pflag.Flag, value string) error {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		values := []string{}
+		if value != "" {
+			values = strings.Split(value, ",")
+		}
+		return slice.Replace(values)
+	}
+	return flag.Value.Set(value)
+}
+
+// addPresetSaveFlags lets preset save take the flags of transcribe.
+func addPresetSaveFlags(transcribe *cobra.Command) {
+	presetSaveCmd.Flags().AddFlagSet(transcribe.PersistentFlags())
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetSaveCmd)
+	presetCmd.AddCommand(presetListCmd)
+	presetCmd.AddCommand(presetShowCmd)
+	presetCmd.AddCommand(presetDeleteCmd)
+	presetListCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	presetShowCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	presetCmd.PersistentFlags().Bool("test", false, "Flag for test executing purpose")
+	presetCmd.PersistentFlags().MarkHidden("test")
+}
@@ -0,0 +1,36 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// redactedAudioCmd represents the redacted-audio command
+var redactedAudioCmd = &cobra.Command{
+	Use:   "redacted-audio [transcription_id]",
+	Short: "Download the PII-redacted audio of a transcription",
+	Long: `Download the audio of a transcription with its personally identifiable
+information beeped out. The file must have been transcribed with --redact_pii
+and --redact_pii_audio. The CLI waits until the redacted audio is ready.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.RedactedAudioFlags
+		flags.Output, _ = cmd.Flags().GetString("output")
+		flags.UrlOnly, _ = cmd.Flags().GetBool("url-only")
+
+		client := U.Authenticate()
+		U.DownloadRedactedAudio(client, args[0], flags)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redactedAudioCmd)
+	redactedAudioCmd.Flags().StringP("output", "o", "", "The file to save the redacted audio to. Defaults to [id].mp3 or [id].wav in the current directory.")
+	redactedAudioCmd.Flags().Bool("url-only", false, "Print the URL of the redacted audio instead of downloading it.")
+	redactedAudioCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	redactedAudioCmd.Flags().MarkHidden("test")
+}
@@ -7,14 +7,30 @@ import (
 	"fmt"
 	"os"
 
+	C "github.com/AssemblyAI/assemblyai-cli/client"
 	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	U "github.com/AssemblyAI/assemblyai-cli/utils"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var VERSION string
 
+// globalFlagKeys maps the global flags to the config keys they override.
+var globalFlagKeys = map[string]string{
+	"token":           "config.token",
+	"base-url":        "api.base_url",
+	"proxy":           "api.proxy",
+	"ca-bundle":       "api.ca_bundle",
+	"client-cert":     "api.client_cert",
+	"client-key":      "api.client_key",
+	"connect-timeout": "api.connect_timeout",
+	"read-timeout":    "api.read_timeout",
+	"max-retries":     "api.max_retries",
+	"retry-timeout":   "api.retry_timeout",
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "assemblyai",
 	Short: "AssemblyAI CLI",
@@ -23,6 +39,24 @@ assemblyai config [token]`,
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		U.ProfileOverride, _ = cmd.Flags().GetString("profile")
+		if U.ProfileOverride != "" || os.Getenv("ASSEMBLYAI_PROFILE") != "" {
+			if err := U.ValidateProfileName(U.ActiveProfile()); err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: "Invalid profile: " + err.Error() + ".",
+				}
+				U.PrintError(printErrorProps)
+			}
+		}
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			if key, ok := globalFlagKeys[flag.Name]; ok {
+				U.FlagOverrides[key] = flag.Value.String()
+			}
+		})
+		U.CheckForUpdates(VERSION)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		versionFlag, _ := cmd.Flags().GetBool("version")
 		if versionFlag {
@@ -38,7 +72,6 @@ func Execute() {
 		godotenv.Load()
 		VERSION = os.Getenv("VERSION")
 	}
-	U.CheckForUpdates(VERSION)
 	if err := rootCmd.Execute(); err != nil {
 		printErrorProps := S.PrintErrorProps{
 			Error:   err,
@@ -51,6 +84,17 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "Check current installed version.")
+	rootCmd.PersistentFlags().String("profile", "", "The configuration profile to use. Defaults to the ASSEMBLYAI_PROFILE environment variable, then the profile selected with config profiles use.")
+	rootCmd.PersistentFlags().String("token", "", "The API token to use instead of the stored one. Defaults to the ASSEMBLYAI_API_KEY environment variable.")
+	rootCmd.PersistentFlags().String("base-url", C.DefaultBaseURL, "The AssemblyAI API host to send requests to.")
+	rootCmd.PersistentFlags().String("proxy", "", "URL of the HTTP(S) proxy to use. Defaults to the HTTPS_PROXY environment variable.")
+	rootCmd.PersistentFlags().String("ca-bundle", "", "Path to a PEM file with additional certificate authorities to trust.")
+	rootCmd.PersistentFlags().String("client-cert", "", "Path to a PEM client certificate for mutual TLS.")
+	rootCmd.PersistentFlags().String("client-key", "", "Path to the PEM key of the client certificate.")
+	rootCmd.PersistentFlags().Duration("connect-timeout", U.DefaultConnectTimeout, "The maximum time to establish a connection.")
+	rootCmd.PersistentFlags().Duration("read-timeout", U.DefaultReadTimeout, "The maximum time to wait for a response once a request is sent.")
+	rootCmd.PersistentFlags().Int("max-retries", C.DefaultRetryPolicy.MaxRetries, "How many times failed API requests are retried.")
+	rootCmd.PersistentFlags().Duration("retry-timeout", C.DefaultRetryPolicy.Timeout, "The maximum time spent retrying a single API request.")
 	rootCmd.Flags().Bool("test", false, "Flag for test executing purpose")
 	rootCmd.Flags().MarkHidden("test")
 }
@@ -0,0 +1,53 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the transcriptions kept on this machine",
+	Long: `Search the words of the completed transcriptions in the local history, without
+calling the API. Words next to each other must all match, "quoted phrases"
+match consecutive words, OR matches either side, NOT or a leading - excludes
+transcriptions, and parentheses group. A word ending with * matches every word
+starting with it.`,
+	Example: `  assemblyai search competitor
+  assemblyai search '"pricing page" OR discount -internal'`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.SearchFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		flags.IdsOnly, _ = cmd.Flags().GetBool("ids-only")
+		flags.Context, _ = cmd.Flags().GetInt("context")
+		flags.Limit, _ = cmd.Flags().GetInt("limit")
+		if flags.Context < 0 {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("Invalid context"),
+				Message: "--context can't be negative.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		U.SearchTranscripts(strings.Join(args, " "), flags)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntP("context", "C", 0, "The number of words to show around each match.")
+	searchCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	searchCmd.Flags().Bool("ids-only", false, "Only print the IDs of the matching transcriptions.")
+	searchCmd.Flags().IntP("limit", "n", 50, "The maximum number of matches to show. 0 shows them all.")
+	searchCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	searchCmd.Flags().MarkHidden("test")
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// streamCmd represents the stream command
+var streamCmd = &cobra.Command{
+	Use:   "stream [file]",
+	Short: "Transcribe a live audio stream in real time",
+	Long: `Stream audio to AssemblyAI's real-time transcription and print the transcript
+as it's spoken. The audio is read from a WAV file, or as raw 16-bit mono PCM
+from a file, a named pipe, or stdin when no file or - is given.
+
+Partial transcripts are updated in place on a terminal, and final transcripts
+are printed on their own line.`,
+	Example: `  assemblyai stream meeting.wav
+  sox -d -t raw -r 16000 -e signed -b 16 -c 1 - | assemblyai stream --sample-rate 16000`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.StreamFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		flags.Realtime, _ = cmd.Flags().GetBool("realtime")
+		flags.SampleRate, _ = cmd.Flags().GetInt("sample-rate")
+		wordBoost, _ := cmd.Flags().GetStringSlice("word-boost")
+		for _, word := range wordBoost {
+			if word = strings.TrimSpace(word); word != "" {
+				flags.WordBoost = append(flags.WordBoost, word)
+			}
+		}
+		source := ""
+		if len(args) > 0 {
+			source = args[0]
+		}
+
+		client := U.Authenticate()
+		U.Stream(client, source, flags)
+	},
+}
+
+// streamTokenCmd represents the stream token command
+var streamTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Create a temporary token for real-time sessions",
+	Long: `Create a short-lived token that can start real-time sessions, e.g. from a
+browser, without exposing your API key. Sessions must start before the token
+expires.`,
+	Example: `  assemblyai stream token --expires-in 60`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.StreamTokenFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		flags.ExpiresIn, _ = cmd.Flags().GetInt("expires-in")
+		if err := U.ValidateTokenExpiry(flags.ExpiresIn); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Invalid --expires-in: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+
+		client := U.Authenticate()
+		U.CreateStreamToken(client, flags)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(streamCmd)
+	streamCmd.AddCommand(streamTokenCmd)
+	streamCmd.Flags().BoolP("json", "j", false, "Print every partial and final transcript as a line of JSON.")
+	streamCmd.Flags().Bool("realtime", true, "Send files at the speed the audio plays. Set to false to send them as fast as the API allows.")
+	streamCmd.Flags().Int("sample-rate", 0, "The sample rate of the audio. Defaults to the rate of WAV files, or 16000 for raw PCM.")
+	streamCmd.Flags().StringSlice("word-boost", nil, "Words or phrases to make more likely in the transcript, separated by commas.")
+	streamCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	streamCmd.Flags().MarkHidden("test")
+
+	streamTokenCmd.Flags().Int("expires-in", U.MinTokenExpiry, fmt.Sprintf("How long the token can start sessions for, in seconds, from %d to %d.", U.MinTokenExpiry, U.MaxTokenExpiry))
+	streamTokenCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	streamTokenCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	streamTokenCmd.Flags().MarkHidden("test")
+}
@@ -17,18 +17,26 @@ import (
 )
 
 var transcribeCmd = &cobra.Command{
-	Use:   "transcribe <url | path>",
+	Use:   "transcribe <url | path>...",
 	Short: "Transcribe and understand audio with a single AI-powered API",
 	Long: `Automatically convert audio and video files and live audio streams to text with AssemblyAI's Speech-to-Text APIs. 
 	Do more with Audio Intelligence - summarization, content moderation, topic detection, and more. 
 	Powered by cutting-edge AI models.`,
-	Args: cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if manifest, _ := cmd.Flags().GetString("manifest"); manifest != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		var params S.TranscribeParams
 		var flags S.TranscribeFlags
+		applyTranscribeDefaults(cmd)
+		applyPreset(cmd)
 
 		args = cmd.Flags().Args()
-		if len(args) == 0 {
+		manifest, _ := cmd.Flags().GetString("manifest")
+		if len(args) == 0 && manifest == "" {
 			printErrorProps := S.PrintErrorProps{
 				Error:   errors.New("Please provide a URL or a file path"),
 				Message: "Please provide a local file or a URL to be transcribed.",
@@ -36,11 +44,21 @@ var transcribeCmd = &cobra.Command{
 			U.PrintError(printErrorProps)
 			return
 		}
-		params.AudioURL = args[0]
+		isBatch := manifest != "" || len(args) > 1 || U.IsBatchSource(args[0])
+		if !isBatch {
+			params.AudioURL = args[0]
+		}
 
 		flags.Json, _ = cmd.Flags().GetBool("json")
 		flags.Poll, _ = cmd.Flags().GetBool("poll")
-		flags.Srt, _ = cmd.Flags().GetBool("srt")
+		if !isBatch {
+			flags.Outputs = getOutputs(cmd, flags.Json)
+		}
+		flags.Captions = getCaptionOptions(cmd)
+		flags.Segmentation = getSegmentation(cmd)
+		if !isBatch && !checkSegmentation(flags.Segmentation, outputFormats(flags.Outputs)) {
+			return
+		}
 		params.AutoChapters, _ = cmd.Flags().GetBool("auto_chapters")
 		params.AutoHighlights, _ = cmd.Flags().GetBool("auto_highlights")
 		params.ContentModeration, _ = cmd.Flags().GetBool("content_moderation")
@@ -55,99 +73,28 @@ var transcribeCmd = &cobra.Command{
 		params.TopicDetection, _ = cmd.Flags().GetBool("topic_detection")
 		params.Summarization, _ = cmd.Flags().GetBool("summarization")
 		wordBoost, _ := cmd.Flags().GetString("word_boost")
-		if params.DualChannel && params.SpeakerLabels {
-			if cmd.Flags().Lookup("speaker_labels").Changed {
-				printErrorProps := S.PrintErrorProps{
-					Error:   errors.New("Speaker labels are not supported for dual channel audio"),
-					Message: "Speaker labels are not supported for dual channel audio",
-				}
-				U.PrintError(printErrorProps)
-				return
-			}
+		if params.DualChannel && params.SpeakerLabels && !cmd.Flags().Lookup("speaker_labels").Changed {
 			params.SpeakerLabels = false
 		}
 		if wordBoost != "" {
 			params.WordBoost = strings.Split(wordBoost, ",")
 			boostParam, _ := cmd.Flags().GetString("boost_param")
-			if boostParam != "" && boostParam != "low" && boostParam != "default" && boostParam != "high" {
-				printErrorProps := S.PrintErrorProps{
-					Error:   errors.New("Invalid boost_param"),
-					Message: "Please provide a valid boost_param. Valid values are low, default, or high.",
-				}
-				U.PrintError(printErrorProps)
-				return
-			}
 			params.BoostParam = &boostParam
 		}
 		if params.Summarization {
 			params.Punctuate = true
 			params.FormatText = true
-
-			if params.AutoChapters {
-				printErrorProps := S.PrintErrorProps{
-					Error:   errors.New("Auto chapters are not supported for summarization"),
-					Message: "Auto chapters are not supported for summarization",
-				}
-				U.PrintError(printErrorProps)
-				return
-			}
-
 			params.SummaryType, _ = cmd.Flags().GetString("summary_type")
-			if _, ok := S.SummarizationTypeMapReverse[params.SummaryType]; !ok {
-				printErrorProps := S.PrintErrorProps{
-					Error:   errors.New("Invalid summary type"),
-					Message: "Invalid summary type. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization",
-				}
-				U.PrintError(printErrorProps)
-				return
-			}
-			summaryModel, _ := cmd.Flags().GetString("summary_model")
-			if summaryModel != "" {
-				if _, ok := S.SummarizationModelMap[summaryModel]; !ok {
-					printErrorProps := S.PrintErrorProps{
-						Error:   errors.New("Invalid summary model"),
-						Message: "Invalid summary model. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization",
-					}
-					U.PrintError(printErrorProps)
-					return
-				}
-				if !U.Contains(S.SummarizationModelMap[summaryModel], params.SummaryType) {
-					printErrorProps := S.PrintErrorProps{
-						Error:   errors.New("Invalid summary model"),
-						Message: "Cant use summary model " + summaryModel + " with summary type " + params.SummaryType + ". To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization",
-					}
-					U.PrintError(printErrorProps)
-					return
-				}
-				if summaryModel == "conversational" && !params.SpeakerLabels {
-					printErrorProps := S.PrintErrorProps{
-						Error:   errors.New("Speaker labels required for conversational summary model"),
-						Message: "Speaker labels are required for conversational summarization. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization",
-					}
-					U.PrintError(printErrorProps)
-					return
-				}
-				params.SummaryModel = summaryModel
-			}
+			params.SummaryModel, _ = cmd.Flags().GetString("summary_model")
 		}
 
 		if params.RedactPii {
 			policies, _ := cmd.Flags().GetString("redact_pii_policies")
-			policiesArray := strings.Split(policies, ",")
-
-			for _, policy := range policiesArray {
-				if _, ok := S.PIIRedactionPolicyMap[policy]; !ok {
-					printErrorProps := S.PrintErrorProps{
-						Error:   errors.New("Invalid redaction policy"),
-						Message: fmt.Sprintf("%s is not a valid policy. See https://www.assemblyai.com/docs/Models/pii_redaction for the complete list of supported policies.", policy),
-					}
-					U.PrintError(printErrorProps)
-					return
-				}
-			}
-
-			params.RedactPiiPolicies = policiesArray
+			params.RedactPiiPolicies = strings.Split(policies, ",")
 		}
+		params.RedactPiiAudio, _ = cmd.Flags().GetBool("redact_pii_audio")
+		params.RedactPiiAudioQuality, _ = cmd.Flags().GetString("redact_pii_audio_quality")
+		params.RedactPiiSub, _ = cmd.Flags().GetString("redact_pii_sub")
 		webhook := cmd.Flags().Lookup("webhook_url").Value.String()
 		if webhook != "" {
 			params.WebhookURL = webhook
@@ -160,30 +107,10 @@ var transcribeCmd = &cobra.Command{
 				params.WebhookAuthHeaderValue = webhookHeaderValue
 			}
 		}
-		languageDetection, _ := cmd.Flags().GetBool("language_detection")
+		params.LanguageDetection, _ = cmd.Flags().GetBool("language_detection")
 		languageCode, _ := cmd.Flags().GetString("language_code")
-		if languageDetection && languageCode != "" {
-			printErrorProps := S.PrintErrorProps{
-				Error:   errors.New("Invalid language detection"),
-				Message: "Please provide either language detection or language code, not both.",
-			}
-			U.PrintError(printErrorProps)
-			return
-		}
-		if languageDetection && languageCode == "" {
-			params.LanguageDetection = true
-		}
 		if languageCode != "" {
-			if _, ok := S.LanguageMap[languageCode]; !ok {
-				printErrorProps := S.PrintErrorProps{
-					Error:   errors.New("Invalid language code"),
-					Message: "Invalid language code. See https://www.assemblyai.com/docs/Concepts/faq#supported-languages for supported languages.",
-				}
-				U.PrintError(printErrorProps)
-				return
-			}
 			params.LanguageCode = &languageCode
-			params.LanguageDetection = false
 		}
 
 		customSpelling, _ := cmd.Flags().GetString("custom_spelling")
@@ -233,20 +160,28 @@ var transcribeCmd = &cobra.Command{
 					return
 				}
 			}
+			params.CustomSpelling = parsedCustomSpelling
+		}
 
-			err = U.ValidateCustomSpelling(parsedCustomSpelling)
-			if err != nil {
-				printErrorProps := S.PrintErrorProps{
-					Error:   err,
-					Message: "Invalid custom spelling. Please provide a valid custom spelling JSON.",
-				}
-				U.PrintError(printErrorProps)
-				return
+		if err := U.ValidateParams(params); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: err.Error(),
 			}
-			params.CustomSpelling = parsedCustomSpelling
+			U.PrintError(printErrorProps)
+			return
 		}
 
-		U.Transcribe(params, flags)
+		if isBatch {
+			jobs := getBatchJobs(args, manifest, params)
+			batchFlags := getBatchFlags(cmd, flags)
+			client := U.Authenticate()
+			U.TranscribeBatch(client, jobs, batchFlags)
+			return
+		}
+
+		client := U.Authenticate()
+		U.Transcribe(client, params, flags)
 	},
 }
 
@@ -263,9 +198,16 @@ func init() {
 	transcribeCmd.PersistentFlags().BoolP("poll", "p", true, "The CLI will poll the transcription until it's complete.")
 	transcribeCmd.PersistentFlags().BoolP("punctuate", "u", true, "Enable automatic punctuation.")
 	transcribeCmd.PersistentFlags().BoolP("redact_pii", "r", false, "Remove personally identifiable information from the transcription.")
+	transcribeCmd.PersistentFlags().Bool("redact_pii_audio", false, "Also create a copy of the audio with the redacted information beeped out. Download it with the redacted-audio command.")
+	transcribeCmd.PersistentFlags().String("redact_pii_audio_quality", "", "The format of the redacted audio: mp3 or wav. Defaults to mp3.")
+	transcribeCmd.PersistentFlags().String("redact_pii_sub", "", "How redacted information is replaced in the transcription: hash, e.g. ####, or entity_name, e.g. [PERSON_NAME]. Defaults to hash.")
 	transcribeCmd.PersistentFlags().BoolP("sentiment_analysis", "x", false, "Detect the sentiment of each sentence of speech spoken in the file.")
 	transcribeCmd.PersistentFlags().BoolP("speaker_labels", "l", false, "Automatically detect the number of speakers in your audio file, and each word in the transcription text can be associated with its speaker.")
-	transcribeCmd.PersistentFlags().BoolP("srt", "", false, "Generate an SRT file for the audio file transcribed.")
+	addOutputFlags(transcribeCmd, "")
+	transcribeCmd.PersistentFlags().String("manifest", "", "A CSV or JSON Lines file listing the files to transcribe, with optional per-file parameters.")
+	transcribeCmd.PersistentFlags().Int("concurrency", 4, "The number of files transcribed at the same time in a batch.")
+	addCaptionFlags(transcribeCmd)
+	addSegmentationFlags(transcribeCmd)
 	transcribeCmd.PersistentFlags().BoolP("summarization", "m", false, "Generate a single abstractive summary of the entire audio.")
 	transcribeCmd.PersistentFlags().BoolP("topic_detection", "t", false, "Label the topics that are spoken in the file.")
 	transcribeCmd.PersistentFlags().StringP("boost_param", "z", "", "Control how much weight should be applied to your boosted keywords/phrases. This value can be either low, default, or high.")
@@ -283,4 +225,82 @@ func init() {
 	transcribeCmd.Flags().MarkHidden("test")
 
 	rootCmd.AddCommand(transcribeCmd)
+	registerTranscribeSettings(transcribeCmd)
+	addPresetSaveFlags(transcribeCmd)
+	transcribeCmd.Flags().String("preset", "", "The preset of flags to use. Flags given explicitly take precedence over it.")
+}
+
+// getBatchJobs collects the jobs of a batch from the arguments and the
+// manifest, all based on params.
+func getBatchJobs(args []string, manifest string, params S.TranscribeParams) []S.BatchJob {
+	sources, err := U.CollectSources(args)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Could not find the files to transcribe: " + err.Error() + ".",
+		}
+		U.PrintError(printErrorProps)
+		return nil
+	}
+	jobs := U.NewBatchJobs(sources, params)
+	if manifest != "" {
+		manifestJobs, err := U.ReadManifest(manifest, params)
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Could not read the manifest: " + err.Error() + ".",
+			}
+			U.PrintError(printErrorProps)
+			return nil
+		}
+		jobs = append(jobs, manifestJobs...)
+	}
+	return jobs
+}
+
+// getBatchFlags reads the output flags of a batch. Every transcript gets one
+// [name].[extension] file per format in the --output directory.
+func getBatchFlags(cmd *cobra.Command, flags S.TranscribeFlags) S.BatchFlags {
+	batchFlags := S.BatchFlags{Poll: flags.Poll, Captions: flags.Captions, Segmentation: flags.Segmentation}
+	batchFlags.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	batchFlags.OutputDir, _ = cmd.Flags().GetString("output")
+	if batchFlags.OutputDir == "" || batchFlags.OutputDir == "-" {
+		batchFlags.OutputDir = "."
+	}
+	formats, _ := cmd.Flags().GetStringSlice("format")
+	if flags.Json && !U.Contains(formats, "json") {
+		formats = append(formats, "json")
+	}
+	if srt, _ := cmd.Flags().GetBool("srt"); srt && !U.Contains(formats, "srt") {
+		formats = append(formats, "srt")
+	}
+	if vtt, _ := cmd.Flags().GetBool("vtt"); vtt && !U.Contains(formats, "vtt") {
+		formats = append(formats, "vtt")
+	}
+	if len(formats) == 0 {
+		formats = []string{"text"}
+	}
+	for _, format := range formats {
+		if _, ok := U.LookupFormatter(format); !ok {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("Invalid format"),
+				Message: fmt.Sprintf("Invalid format %s. Valid formats are %s, a batch writes each of them to the --output directory.", format, strings.Join(U.FormatNames(), ", ")),
+			}
+			U.PrintError(printErrorProps)
+			return batchFlags
+		}
+	}
+	if batchFlags.Concurrency < 1 {
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New("Invalid concurrency"),
+			Message: "The concurrency must be at least 1.",
+		}
+		U.PrintError(printErrorProps)
+		return batchFlags
+	}
+	if !checkSegmentation(flags.Segmentation, formats) {
+		return batchFlags
+	}
+	batchFlags.Formats = formats
+	return batchFlags
 }
@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	U "github.com/AssemblyAI/assemblyai-cli/utils"
 	"github.com/spf13/cobra"
 )
@@ -17,9 +18,17 @@ var validateCmd = &cobra.Command{
 	Short:  "Validate your token",
 	Long:   `Seamlessly validate your AssemblyAI token.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		U.Token = U.GetStoredToken()
-		if U.Token != "" {
-			fmt.Printf("Your Token is %s\n", U.Token)
+		token, source, err := U.LookupToken()
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: fmt.Sprintf("Could not read the token from %s: %s.", source, err),
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+		if token != "" {
+			fmt.Printf("Your Token is %s, from %s.\n", U.MaskToken(token), source)
 			return
 		} else {
 			fmt.Println("Please start by running \033[1m\033[34massemblyai config [token]\033[0m")
@@ -0,0 +1,52 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	U "github.com/AssemblyAI/assemblyai-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// wordsCmd represents the words command
+var wordsCmd = &cobra.Command{
+	Use:   "words [transcription_id]",
+	Short: "Search the words of a transcription",
+	Long: `Search a completed transcription for words or phrases through the API, and
+show how often each of them occurs and when.`,
+	Example: `  assemblyai words 6cm2xpyyg0-1a8f-4f10-a6e6-cca4fd1fc3f5 --terms "refund,cancel"`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var flags S.WordsFlags
+		flags.Json, _ = cmd.Flags().GetBool("json")
+		terms, _ := cmd.Flags().GetStringSlice("terms")
+		for _, term := range terms {
+			if term = strings.TrimSpace(term); term != "" {
+				flags.Terms = append(flags.Terms, term)
+			}
+		}
+		if len(flags.Terms) == 0 {
+			printErrorProps := S.PrintErrorProps{
+				Error:   errors.New("No terms provided."),
+				Message: "You must provide the words to search for with --terms.",
+			}
+			U.PrintError(printErrorProps)
+			return
+		}
+
+		client := U.Authenticate()
+		U.SearchWords(client, args[0], flags)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(wordsCmd)
+	wordsCmd.Flags().BoolP("json", "j", false, "If true, the CLI will output the JSON.")
+	wordsCmd.Flags().StringSliceP("terms", "t", nil, "The words or phrases to search for, separated by commas.")
+	wordsCmd.Flags().Bool("test", false, "Flag for test executing purpose")
+	wordsCmd.Flags().MarkHidden("test")
+}
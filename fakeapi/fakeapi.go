@@ -0,0 +1,507 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+
+// Package fakeapi implements an in-memory stand-in for the AssemblyAI API, so
+// the client and the CLI can be tested without network access.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Failure is an error response injected with Server.Fail.
+type Failure struct {
+	Status     int
+	Message    string
+	RetryAfter int
+}
+
+// Server is a fake AssemblyAI API. Transcripts move through the statuses set
+// with SetStatuses, one step per GET, and get the fields set with SetResult
+// merged in once they complete.
+type Server struct {
+	*httptest.Server
+
+	// Token is the only API token the server accepts.
+	Token string
+
+	mu           sync.Mutex
+	statuses     []string
+	errorMessage string
+	result       map[string]interface{}
+	transcripts  map[string]*transcript
+	order        []string
+	uploads      map[string][]byte
+	failures     map[string][]Failure
+	requests     []string
+	deleted      []string
+	realtime     []*RealtimeSession
+	lemur        []LemurRequest
+	nextID       int
+}
+
+type transcript struct {
+	fields map[string]interface{}
+	step   int
+}
+
+// New starts a fake API accepting token. Call Close when done.
+func New(token string) *Server {
+	s := &Server{
+		Token:        token,
+		statuses:     []string{"queued", "processing", "completed"},
+		errorMessage: "Transcoding failed.",
+		result:       DefaultResult(),
+		transcripts:  map[string]*transcript{},
+		uploads:      map[string][]byte{},
+		failures:     map[string][]Failure{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SetStatuses sets the statuses new transcripts go through. When the last one
+// is "error", transcripts fail with the message set with SetError.
+func (s *Server) SetStatuses(statuses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = statuses
+}
+
+// SetError sets the error of transcripts that fail.
+func (s *Server) SetError(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorMessage = message
+}
+
+// SetResult sets the fields added to a transcript once it completes.
+func (s *Server) SetResult(result map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+}
+
+// Fail makes the next request matching method and path fail. Calling it
+// several times queues several failures.
+func (s *Server) Fail(method string, path string, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.failures[key] = append(s.failures[key], failure)
+}
+
+// Requests returns every request received so far as "METHOD path".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Upload returns the content of an uploaded file by its upload URL.
+func (s *Server) Upload(uploadURL string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploads[uploadURL]
+}
+
+// AudioURL returns a URL served by the fake that can be transcribed.
+func (s *Server) AudioURL(name string) string {
+	return s.URL + "/audio/" + name
+}
+
+// AddTranscript stores a transcript with the given fields, e.g. one that is
+// already completed.
+func (s *Server) AddTranscript(id string, fields map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := map[string]interface{}{}
+	for key, value := range fields {
+		stored[key] = value
+	}
+	stored["id"] = id
+	if _, ok := stored["status"]; !ok {
+		stored["status"] = "completed"
+	}
+	if _, ok := stored["created"]; !ok {
+		stored["created"] = time.Now().UTC().Format("2006-01-02T15:04:05.000000")
+	}
+	s.transcripts[id] = &transcript{fields: stored, step: len(s.statuses)}
+	s.order = append(s.order, id)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v2/realtime/ws" {
+		// Sessions are long-lived, so they only lock the server to record
+		// what they receive.
+		s.handleRealtime(w, r)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if strings.HasPrefix(r.URL.Path, "/audio/") {
+		w.Write([]byte("audio"))
+		return
+	}
+	key := r.Method + " " + r.URL.Path
+	if queued := s.failures[key]; len(queued) > 0 {
+		s.failures[key] = queued[1:]
+		if queued[0].RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(queued[0].RetryAfter))
+		}
+		writeError(w, queued[0].Status, queued[0].Message)
+		return
+	}
+	if r.Header.Get("Authorization") != s.Token {
+		writeError(w, http.StatusUnauthorized, "Authentication error, API token missing/invalid")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == "GET" && path == "/v2/account":
+		writeJSON(w, map[string]interface{}{
+			"id":              1,
+			"is_verified":     true,
+			"current_balance": map[string]interface{}{"amount": 10, "currency": "USD"},
+		})
+	case r.Method == "POST" && path == "/v2/realtime/token":
+		s.handleRealtimeToken(w, r)
+	case r.Method == "POST" && strings.HasPrefix(path, "/lemur/v3/generate/"):
+		endpoint := strings.TrimPrefix(path, "/lemur/v3/generate/")
+		switch endpoint {
+		case "task", "summary", "question-answer", "action-items":
+			s.handleLemur(w, r, endpoint)
+		default:
+			writeError(w, http.StatusNotFound, "Not found")
+		}
+	case r.Method == "POST" && path == "/v2/upload":
+		s.handleUpload(w, r)
+	case r.Method == "POST" && path == "/v2/transcript":
+		s.handleSubmit(w, r)
+	case r.Method == "GET" && path == "/v2/transcript":
+		s.handleList(w, r)
+	case r.Method == "GET" && strings.HasPrefix(path, "/v2/transcript/") && strings.HasSuffix(path, "/redacted-audio"):
+		s.handleRedactedAudio(w, strings.TrimSuffix(strings.TrimPrefix(path, "/v2/transcript/"), "/redacted-audio"))
+	case r.Method == "GET" && strings.HasPrefix(path, "/v2/transcript/") && strings.HasSuffix(path, "/sentences"):
+		s.handleSegments(w, strings.TrimSuffix(strings.TrimPrefix(path, "/v2/transcript/"), "/sentences"), "sentences")
+	case r.Method == "GET" && strings.HasPrefix(path, "/v2/transcript/") && strings.HasSuffix(path, "/paragraphs"):
+		s.handleSegments(w, strings.TrimSuffix(strings.TrimPrefix(path, "/v2/transcript/"), "/paragraphs"), "paragraphs")
+	case r.Method == "GET" && strings.HasPrefix(path, "/v2/transcript/") && strings.HasSuffix(path, "/word-search"):
+		s.handleWordSearch(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/v2/transcript/"), "/word-search"))
+	case r.Method == "GET" && strings.HasPrefix(path, "/v2/transcript/"):
+		s.handleGet(w, strings.TrimPrefix(path, "/v2/transcript/"))
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/v2/transcript/"):
+		s.handleDelete(w, strings.TrimPrefix(path, "/v2/transcript/"))
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.nextID++
+	uploadURL := fmt.Sprintf("%s/upload/%d", s.URL, s.nextID)
+	s.uploads[uploadURL] = data
+	writeJSON(w, map[string]string{"upload_url": uploadURL})
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	fields := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if audioURL, _ := fields["audio_url"].(string); audioURL == "" {
+		writeError(w, http.StatusBadRequest, "audio_url is required")
+		return
+	}
+	s.nextID++
+	id := fmt.Sprintf("tr_%d", s.nextID)
+	fields["id"] = id
+	fields["status"] = s.statuses[0]
+	fields["created"] = time.Now().UTC().Format("2006-01-02T15:04:05.000000")
+	fields["text"] = nil
+	fields["words"] = nil
+	s.transcripts[id] = &transcript{fields: fields}
+	s.order = append(s.order, id)
+	writeJSON(w, fields)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, id string) {
+	t, ok := s.transcripts[id]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Transcript lookup error, transcript id not found")
+		return
+	}
+	if t.step < len(s.statuses)-1 {
+		t.step++
+		t.fields["status"] = s.statuses[t.step]
+		switch s.statuses[t.step] {
+		case "completed":
+			for key, value := range s.result {
+				t.fields[key] = value
+			}
+		case "error":
+			t.fields["error"] = s.errorMessage
+		}
+	}
+	writeJSON(w, t.fields)
+}
+
+func (s *Server) handleRedactedAudio(w http.ResponseWriter, id string) {
+	t, ok := s.transcripts[id]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Transcript lookup error, transcript id not found")
+		return
+	}
+	if t.fields["status"] != "completed" {
+		writeError(w, http.StatusBadRequest, "This transcript has a status of "+fmt.Sprint(t.fields["status"])+". Transcripts must have a status of completed before requesting redacted audio.")
+		return
+	}
+	if t.fields["redact_pii_audio"] != true {
+		writeError(w, http.StatusBadRequest, "Redacted audio is only available for transcripts submitted with redact_pii_audio.")
+		return
+	}
+	extension := "mp3"
+	if t.fields["redact_pii_audio_quality"] == "wav" {
+		extension = "wav"
+	}
+	writeJSON(w, map[string]interface{}{
+		"status":             "redacted_audio_ready",
+		"redacted_audio_url": s.AudioURL(id + "." + extension),
+	})
+}
+
+type word struct {
+	Text       string  `json:"text"`
+	Start      int64   `json:"start"`
+	End        int64   `json:"end"`
+	Confidence float64 `json:"confidence"`
+	Speaker    *string `json:"speaker"`
+}
+
+// words returns the words of a completed transcript.
+func (t *transcript) words() []word {
+	var words []word
+	data, _ := json.Marshal(t.fields["words"])
+	json.Unmarshal(data, &words)
+	return words
+}
+
+func sameSpeaker(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// handleSegments splits a transcript into sentences at the words ending with
+// a period, question or exclamation mark, or into paragraphs at speaker
+// changes.
+func (s *Server) handleSegments(w http.ResponseWriter, id string, kind string) {
+	t, ok := s.transcripts[id]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Transcript lookup error, transcript id not found")
+		return
+	}
+	if t.fields["status"] != "completed" {
+		writeError(w, http.StatusBadRequest, "This transcript has a status of "+fmt.Sprint(t.fields["status"])+". Transcripts must have a status of completed before requesting "+kind+".")
+		return
+	}
+	words := t.words()
+	segments := []interface{}{}
+	start := 0
+	for i, current := range words {
+		last := i == len(words)-1
+		if !last {
+			switch kind {
+			case "sentences":
+				if !strings.ContainsAny(current.Text[len(current.Text)-1:], ".?!") {
+					continue
+				}
+			case "paragraphs":
+				if sameSpeaker(current.Speaker, words[i+1].Speaker) {
+					continue
+				}
+			}
+		}
+		texts := []string{}
+		for _, segmentWord := range words[start : i+1] {
+			texts = append(texts, segmentWord.Text)
+		}
+		segments = append(segments, map[string]interface{}{
+			"text":       strings.Join(texts, " "),
+			"start":      words[start].Start,
+			"end":        current.End,
+			"confidence": current.Confidence,
+			"speaker":    words[start].Speaker,
+			"words":      words[start : i+1],
+		})
+		start = i + 1
+	}
+	writeJSON(w, map[string]interface{}{
+		"id":             id,
+		"confidence":     0.98,
+		"audio_duration": t.fields["audio_duration"],
+		kind:             segments,
+	})
+}
+
+func (s *Server) handleWordSearch(w http.ResponseWriter, r *http.Request, id string) {
+	t, ok := s.transcripts[id]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Transcript lookup error, transcript id not found")
+		return
+	}
+	if t.fields["status"] != "completed" {
+		writeError(w, http.StatusBadRequest, "This transcript has a status of "+fmt.Sprint(t.fields["status"])+". Transcripts must have a status of completed before requesting words.")
+		return
+	}
+	words := t.words()
+
+	matches := []interface{}{}
+	total := 0
+	for _, term := range strings.Split(r.URL.Query().Get("words"), ",") {
+		parts := strings.Fields(normalizeWord(term))
+		if len(parts) == 0 {
+			continue
+		}
+		timestamps := [][2]int64{}
+		indexes := []int{}
+		for i := 0; i+len(parts) <= len(words); i++ {
+			found := true
+			for j, part := range parts {
+				if normalizeWord(words[i+j].Text) != part {
+					found = false
+					break
+				}
+			}
+			if found {
+				timestamps = append(timestamps, [2]int64{words[i].Start, words[i+len(parts)-1].End})
+				indexes = append(indexes, i)
+			}
+		}
+		total += len(indexes)
+		matches = append(matches, map[string]interface{}{
+			"text":       strings.TrimSpace(term),
+			"count":      len(indexes),
+			"timestamps": timestamps,
+			"indexes":    indexes,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"id": id, "total_count": total, "matches": matches})
+}
+
+// normalizeWord lowercases text and drops its punctuation, like the word
+// search of the API.
+func normalizeWord(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || r == '\'' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, id string) {
+	t, ok := s.transcripts[id]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Transcript lookup error, transcript id not found")
+		return
+	}
+	for _, key := range []string{"words", "utterances", "chapters", "entities", "summary"} {
+		delete(t.fields, key)
+	}
+	t.fields["text"] = "Deleted by user."
+	t.fields["audio_url"] = "http://deleted_by_user"
+	s.deleted = append(s.deleted, id)
+	writeJSON(w, t.fields)
+}
+
+// Deleted returns the IDs of the transcripts deleted so far.
+func (s *Server) Deleted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.deleted...)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 10
+	if value := query.Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+	}
+	beforeID := query.Get("before_id")
+	status := query.Get("status")
+
+	// Newest first, starting after before_id when given.
+	items := []interface{}{}
+	var prevURL interface{}
+	skipping := beforeID != ""
+	for i := len(s.order) - 1; i >= 0; i-- {
+		id := s.order[i]
+		if skipping {
+			skipping = id != beforeID
+			continue
+		}
+		t, ok := s.transcripts[id]
+		if !ok || (status != "" && t.fields["status"] != status) {
+			continue
+		}
+		if len(items) == limit {
+			prevURL = fmt.Sprintf("%s/v2/transcript?limit=%d&before_id=%s", s.URL, limit, items[len(items)-1].(map[string]interface{})["id"])
+			break
+		}
+		items = append(items, map[string]interface{}{
+			"id":             id,
+			"resource_url":   s.URL + "/v2/transcript/" + id,
+			"status":         t.fields["status"],
+			"created":        t.fields["created"],
+			"audio_url":      t.fields["audio_url"],
+			"audio_duration": t.fields["audio_duration"],
+			"error":          t.fields["error"],
+		})
+	}
+	writeJSON(w, map[string]interface{}{
+		"page_details": map[string]interface{}{
+			"limit":        limit,
+			"result_count": len(items),
+			"current_url":  s.URL + r.URL.String(),
+			"prev_url":     prevURL,
+			"next_url":     nil,
+		},
+		"transcripts": items,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", "fake-request")
+	w.WriteHeader(status)
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LemurRequest records a request received by a LeMUR endpoint.
+type LemurRequest struct {
+	Endpoint string
+	Params   map[string]interface{}
+}
+
+// LemurRequests returns the LeMUR requests received so far.
+func (s *Server) LemurRequests() []LemurRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]LemurRequest(nil), s.lemur...)
+}
+
+// handleLemur answers a LeMUR request over completed transcripts with a
+// response naming the endpoint and the transcripts, so tests can check what
+// was sent.
+func (s *Server) handleLemur(w http.ResponseWriter, r *http.Request, endpoint string) {
+	var params struct {
+		TranscriptIDs []string `json:"transcript_ids"`
+		Prompt        string   `json:"prompt"`
+		Questions     []struct {
+			Question string `json:"question"`
+		} `json:"questions"`
+	}
+	fields := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	data, _ := json.Marshal(fields)
+	json.Unmarshal(data, &params)
+	s.lemur = append(s.lemur, LemurRequest{Endpoint: endpoint, Params: fields})
+
+	if len(params.TranscriptIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "transcript_ids must not be empty")
+		return
+	}
+	for _, id := range params.TranscriptIDs {
+		t, ok := s.transcripts[id]
+		if !ok || t.fields["status"] != "completed" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Transcript %s is not completed", id))
+			return
+		}
+	}
+
+	s.nextID++
+	transcripts := strings.Join(params.TranscriptIDs, ", ")
+	var response interface{}
+	switch endpoint {
+	case "task":
+		if params.Prompt == "" {
+			writeError(w, http.StatusBadRequest, "prompt is required")
+			return
+		}
+		response = fmt.Sprintf("Response to %q for %s.", params.Prompt, transcripts)
+	case "summary":
+		response = fmt.Sprintf("Summary of %s.\nThe speakers talk about speech recognition.", transcripts)
+	case "action-items":
+		response = fmt.Sprintf("Action items of %s:\n- Follow up on speech recognition.", transcripts)
+	case "question-answer":
+		if len(params.Questions) == 0 {
+			writeError(w, http.StatusBadRequest, "questions must not be empty")
+			return
+		}
+		answers := []map[string]string{}
+		for index, question := range params.Questions {
+			answers = append(answers, map[string]string{
+				"question": question.Question,
+				"answer":   fmt.Sprintf("Answer %d for %s.", index+1, transcripts),
+			})
+		}
+		response = answers
+	}
+	writeJSON(w, map[string]interface{}{
+		"request_id": fmt.Sprintf("lemur_%d", s.nextID),
+		"response":   response,
+		"usage":      map[string]int{"input_tokens": 100, "output_tokens": 20},
+	})
+}
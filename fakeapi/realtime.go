@@ -0,0 +1,175 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package fakeapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wordsPerFinal is the number of audio messages after which the fake ends a
+// sentence with a final transcript.
+const wordsPerFinal = 4
+
+// RealtimeSession records a real-time session received by the server.
+type RealtimeSession struct {
+	Query      url.Values
+	Audio      []byte
+	Terminated bool
+}
+
+// RealtimeSessions returns the real-time sessions received so far.
+func (s *Server) RealtimeSessions() []RealtimeSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := []RealtimeSession{}
+	for _, session := range s.realtime {
+		copied := *session
+		copied.Audio = append([]byte(nil), session.Audio...)
+		sessions = append(sessions, copied)
+	}
+	return sessions
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (s *Server) handleRealtimeToken(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ExpiresIn int `json:"expires_in"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if params.ExpiresIn < 60 || params.ExpiresIn > 360000 {
+		writeError(w, http.StatusBadRequest, "expires_in must be between 60 and 360000")
+		return
+	}
+	s.nextID++
+	writeJSON(w, map[string]string{"token": fmt.Sprintf("rt_token_%d_%d", s.nextID, params.ExpiresIn)})
+}
+
+// handleRealtime transcribes every audio message as the next word of the
+// sample transcript: it sends a partial transcript with the words of the
+// current sentence, and a final one every wordsPerFinal messages and when the
+// session is terminated.
+func (s *Server) handleRealtime(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	session := &RealtimeSession{Query: r.URL.Query()}
+	s.realtime = append(s.realtime, session)
+	s.nextID++
+	id := fmt.Sprintf("rt_%d", s.nextID)
+	s.mu.Unlock()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	closeWith := func(code int, text string) {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, text))
+	}
+	if r.Header.Get("Authorization") != s.Token {
+		closeWith(4001, "Not Authorized")
+		return
+	}
+	sampleRate, err := strconv.Atoi(r.URL.Query().Get("sample_rate"))
+	if err != nil || sampleRate <= 0 {
+		closeWith(4000, "Sample rate must be a positive integer")
+		return
+	}
+	conn.WriteJSON(map[string]interface{}{
+		"message_type": "SessionBegins",
+		"session_id":   id,
+		"expires_at":   time.Now().UTC().Add(time.Hour).Format("2006-01-02T15:04:05.000000"),
+	})
+
+	var received, sentenceStart int
+	words := []interface{}{}
+	transcript := func(messageType string) map[string]interface{} {
+		texts := []string{}
+		for _, word := range words {
+			texts = append(texts, word.(map[string]interface{})["text"].(string))
+		}
+		return map[string]interface{}{
+			"message_type": messageType,
+			"audio_start":  sentenceStart,
+			"audio_end":    received * 1000 / (2 * sampleRate),
+			"confidence":   0.98,
+			"text":         strings.Join(texts, " "),
+			"words":        words,
+			"created":      time.Now().UTC().Format("2006-01-02T15:04:05.000000"),
+		}
+	}
+	sendFinal := func() {
+		final := transcript("FinalTranscript")
+		final["punctuated"] = true
+		final["text_formatted"] = true
+		conn.WriteJSON(final)
+		sentenceStart = received * 1000 / (2 * sampleRate)
+		words = []interface{}{}
+	}
+
+	for count := 0; ; count++ {
+		var message struct {
+			AudioData        *string `json:"audio_data"`
+			TerminateSession bool    `json:"terminate_session"`
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			closeWith(4101, "Endpoint received message that is not JSON")
+			return
+		}
+		if message.TerminateSession {
+			if len(words) > 0 {
+				sendFinal()
+			}
+			s.mu.Lock()
+			session.Terminated = true
+			s.mu.Unlock()
+			conn.WriteJSON(map[string]string{"message_type": "SessionTerminated"})
+			closeWith(websocket.CloseNormalClosure, "")
+			return
+		}
+		if message.AudioData == nil {
+			conn.WriteJSON(map[string]string{"error": "Invalid JSON: audio_data is missing"})
+			continue
+		}
+		audio, err := base64.StdEncoding.DecodeString(*message.AudioData)
+		if err != nil {
+			closeWith(4100, "Endpoint received invalid JSON")
+			return
+		}
+		s.mu.Lock()
+		session.Audio = append(session.Audio, audio...)
+		s.mu.Unlock()
+
+		start := received * 1000 / (2 * sampleRate)
+		received += len(audio)
+		word := sampleWords[count%len(sampleWords)]
+		words = append(words, map[string]interface{}{
+			"text":       word[1],
+			"start":      start,
+			"end":        received * 1000 / (2 * sampleRate),
+			"confidence": 0.98,
+		})
+		if len(words) == wordsPerFinal {
+			sendFinal()
+		} else {
+			conn.WriteJSON(transcript("PartialTranscript"))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package fakeapi
+
+import "strings"
+
+// sampleWords is the transcript returned by DefaultResult, as speaker and
+// text of each word. Words are 400ms long with a 100ms gap in between.
+var sampleWords = [][2]string{
+	{"A", "Hello"}, {"A", "and"}, {"A", "welcome"}, {"A", "to"}, {"A", "the"}, {"A", "show."},
+	{"A", "Today"}, {"A", "we"}, {"A", "talk"}, {"A", "about"}, {"A", "speech"}, {"A", "recognition."},
+	{"B", "Thanks"}, {"B", "for"}, {"B", "having"}, {"B", "me."},
+	{"B", "It's"}, {"B", "great"}, {"B", "to"}, {"B", "be"}, {"B", "here."},
+}
+
+// DefaultResult returns the fields of the sample completed transcript.
+func DefaultResult() map[string]interface{} {
+	words := []interface{}{}
+	utterances := []interface{}{}
+	texts := []string{}
+	var utterance map[string]interface{}
+	var utteranceTexts []string
+	for i, word := range sampleWords {
+		start := i * 500
+		end := start + 400
+		w := map[string]interface{}{
+			"text":       word[1],
+			"start":      start,
+			"end":        end,
+			"confidence": 0.98,
+			"speaker":    word[0],
+		}
+		words = append(words, w)
+		texts = append(texts, word[1])
+
+		if utterance == nil || utterance["speaker"] != word[0] {
+			if utterance != nil {
+				utterance["text"] = strings.Join(utteranceTexts, " ")
+				utterances = append(utterances, utterance)
+			}
+			utterance = map[string]interface{}{"speaker": word[0], "start": start, "confidence": 0.98, "words": []interface{}{}}
+			utteranceTexts = nil
+		}
+		utterance["end"] = end
+		utterance["words"] = append(utterance["words"].([]interface{}), w)
+		utteranceTexts = append(utteranceTexts, word[1])
+	}
+	utterance["text"] = strings.Join(utteranceTexts, " ")
+	utterances = append(utterances, utterance)
+
+	return map[string]interface{}{
+		"text":           strings.Join(texts, " "),
+		"words":          words,
+		"utterances":     utterances,
+		"confidence":     0.98,
+		"audio_duration": 11,
+	}
+}
@@ -2,97 +2,154 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/AssemblyAI/assemblyai-cli/fakeapi"
 	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	"github.com/AssemblyAI/assemblyai-cli/utils"
 	U "github.com/AssemblyAI/assemblyai-cli/utils"
 )
 
-func TestVersion(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "-v", "--test").Output()
+const fakeToken = "fake-token"
+
+// binary is the CLI built once for all tests. Every test runs it with its own
+// home folder against a fake API, so nothing touches the network.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "assemblyai-cli-test")
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "assemblyai")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		fmt.Println(string(out))
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+type cli struct {
+	home   string
+	server *fakeapi.Server
+	// env is added to the environment of every run, after the defaults.
+	env []string
+	// dir is the working directory of every run, if set.
+	dir string
+}
+
+// newCLI starts a fake API and returns a CLI environment pointing at it.
+func newCLI(t *testing.T) *cli {
+	t.Helper()
+	server := fakeapi.New(fakeToken)
+	t.Cleanup(server.Close)
+	return &cli{home: t.TempDir(), server: server}
+}
+
+// run executes the CLI and returns its standard output and exit code.
+func (c *cli) run(args ...string) (string, int) {
+	out, err := c.command(args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode()
+	}
+	return string(out), 0
+}
+
+// command returns the command running the CLI with args, for tests that
+// need to interact with it while it runs.
+func (c *cli) command(args ...string) *exec.Cmd {
+	cmd := exec.Command(binary, append(args, "--test")...)
+	// Tokens are kept out of the keyring of the machine running the tests,
+	// and times are shown in UTC whatever its time zone.
+	cmd.Env = append(os.Environ(), "HOME="+c.home, "ASSEMBLYAI_BASE_URL="+c.server.URL, "ASSEMBLYAI_TOKEN_STORAGE=plaintext", "TZ=UTC")
+	cmd.Env = append(cmd.Env, c.env...)
+	cmd.Dir = c.dir
+	return cmd
+}
+
+// configure authenticates the CLI with the fake API's token.
+func (c *cli) configure(t *testing.T) {
+	t.Helper()
+	if out, _ := c.run("config", fakeToken); out != "You're now authenticated.\n" {
+		t.Fatalf("Could not configure the CLI: %s", out)
 	}
+}
+
+func TestVersion(t *testing.T) {
+	out, _ := newCLI(t).run("-v")
 	version := utils.GetEnvWithKey("VERSION")
 	if version == nil {
 		t.Error("VERSION not set")
 	}
-	if string(out) != "AssemblyAI CLI "+*version+"\n" {
-		t.Errorf("Expected AssemblyAI CLI v1.13, got %s.", string(out))
+	if out != "AssemblyAI CLI "+*version+"\n" {
+		t.Errorf("Expected AssemblyAI CLI v1.13, got %s.", out)
 	}
 }
 
 func TestValidate(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "validate", "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "Please start by running \033[1m\033[34massemblyai config [token]\033[0m\n" {
-		t.Errorf("Expected Please start by running \033[1m\033[34massemblyai config [token]\033[0m, got %s.", string(out))
+	out, _ := newCLI(t).run("validate")
+	if out != "Please start by running \033[1m\033[34massemblyai config [token]\033[0m\n" {
+		t.Errorf("Expected Please start by running \033[1m\033[34massemblyai config [token]\033[0m, got %s.", out)
 	}
 }
 
 func TestAuthBad(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "config", "invalid", "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != U.INVALID_TOKEN+"\n" {
-		t.Errorf("Expected Something just went wrong. Please try again., got %s.", string(out))
+	out, _ := newCLI(t).run("config", "invalid")
+	if out != U.INVALID_TOKEN+"\n" {
+		t.Errorf("Expected Something just went wrong. Please try again., got %s.", out)
 	}
 }
 
 func TestAuthCorrect(t *testing.T) {
-	token := utils.GetEnvWithKey("TOKEN")
-	out, err := exec.Command("go", "run", "main.go", "config", *token, "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "You're now authenticated.\n" {
-		t.Errorf("Expected You're now authenticated., got %s.", string(out))
+	out, _ := newCLI(t).run("config", fakeToken)
+	if out != "You're now authenticated.\n" {
+		t.Errorf("Expected You're now authenticated., got %s.", out)
 	}
 }
 
 func TestTranscribeInvalidFlags(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "transcribe", "-i", "invalid", "-o", "invalid", "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "\nrequires at least 1 arg(s), only received 0\n" {
-		t.Errorf("Expected requires at least 1 arg(s), only received 0, got %s.", string(out))
+	out, _ := newCLI(t).run("transcribe", "-i", "invalid", "-o", "invalid")
+	if out != "\nrequires at least 1 arg(s), only received 0\n" {
+		t.Errorf("Expected requires at least 1 arg(s), only received 0, got %s.", out)
 	}
 }
 
 func TestTranscribeBadYoutube(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "transcribe", "https://www.youtube.com/watch?vs=m3cSH7jK3UU", "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "\nCould not find YouTube ID in URL\n" {
-		t.Errorf("Expected Could not find YouTube ID in URL, got %s.", string(out))
+	c := newCLI(t)
+	c.configure(t)
+	out, _ := c.run("transcribe", "https://www.youtube.com/watch?vs=m3cSH7jK3UU")
+	if out != "\nCould not find YouTube ID in URL\n" {
+		t.Errorf("Expected Could not find YouTube ID in URL, got %s.", out)
 	}
 }
 
 func TestTranscribeBadFile(t *testing.T) {
-	out, err := exec.Command("go", "run", "main.go", "transcribe", "invalid", "--test").Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "\nError opening file\n" {
-		t.Errorf("Expected Error opening file, got %s.", string(out))
+	c := newCLI(t)
+	c.configure(t)
+	out, _ := c.run("transcribe", "invalid")
+	if out != "\nError opening file\n" {
+		t.Errorf("Expected Error opening file, got %s.", out)
 	}
 }
 
 func TestTranscribeWithFlags(t *testing.T) {
-	out, err := exec.Command(
-		"go",
-		"run",
-		"main.go",
+	c := newCLI(t)
+	c.configure(t)
+	out, _ := c.run(
 		"transcribe",
-		"https://storage.googleapis.com/aai-web-samples/2%20min.ogg",
+		c.server.AudioURL("2 min.ogg"),
 		"--auto_highlights",
 		"--content_moderation",
 		"--entity_detection",
@@ -105,14 +162,12 @@ func TestTranscribeWithFlags(t *testing.T) {
 		"--topic_detection",
 		"-p=false",
 		"-j",
-		"--test",
-	).Output()
-	if err != nil {
-		fmt.Println(err)
-	}
+	)
 
 	var result S.TranscriptResponse
-	json.Unmarshal(out, &result)
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %s.", out)
+	}
 
 	if *result.Status != "queued" {
 		t.Errorf("Expected queued, got %s.", *result.Status)
@@ -150,63 +205,1098 @@ func TestTranscribeWithFlags(t *testing.T) {
 }
 
 func TestTranscribeRestrictions(t *testing.T) {
+	c := newCLI(t)
+	audioURL := c.server.AudioURL("2 min.ogg")
+
 	// Speaker Labels && Dual Channel
-	out, err := exec.Command(
-		"go",
-		"run",
-		"main.go",
-		"transcribe",
-		"https://storage.googleapis.com/aai-web-samples/2%20min.ogg",
-		"--speaker_labels",
-		"--dual_channel",
-		"-p=false",
-		"-j",
-		"--test",
-	).Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "\nSpeaker labels are not supported for dual channel audio\n" {
-		t.Errorf("Expected Speaker labels are not supported for dual channel audio, got %s.", string(out))
+	out, _ := c.run("transcribe", audioURL, "--speaker_labels", "--dual_channel", "-p=false", "-j")
+	if out != "\nSpeaker labels are not supported for dual channel audio\n" {
+		t.Errorf("Expected Speaker labels are not supported for dual channel audio, got %s.", out)
 	}
 
 	// Auto Chapters && Summarization
-	out, err = exec.Command(
-		"go",
-		"run",
-		"main.go",
-		"transcribe",
-		"https://storage.googleapis.com/aai-web-samples/2%20min.ogg",
-		"--auto_chapters",
-		"--summarization",
-		"-p=false",
-		"-j",
-		"--test",
-	).Output()
-	if err != nil {
-		fmt.Println(err)
-	}
-	if string(out) != "\nAuto chapters are not supported for summarization\n" {
-		t.Errorf("Expected Auto chapters are not supported for summarization, got %s.", string(out))
+	out, _ = c.run("transcribe", audioURL, "--auto_chapters", "--summarization", "-p=false", "-j")
+	if out != "\nAuto chapters are not supported for summarization\n" {
+		t.Errorf("Expected Auto chapters are not supported for summarization, got %s.", out)
 	}
 
 	// Language Detection && Language Code
-	out, err = exec.Command(
-		"go",
-		"run",
-		"main.go",
-		"transcribe",
-		"https://storage.googleapis.com/aai-web-samples/2%20min.ogg",
-		"--language_detection",
-		"--language_code=en-US",
-		"-p=false",
-		"-j",
-		"--test",
-	).Output()
-	if err != nil {
-		fmt.Println(err)
+	out, _ = c.run("transcribe", audioURL, "--language_detection", "--language_code=en-US", "-p=false", "-j")
+	if out != "\nPlease provide either language detection or language code, not both.\n" {
+		t.Errorf("Expected Please provide either language detection or language code, not both., got %s.", out)
+	}
+}
+
+func TestTranscribeLocalFile(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	path := filepath.Join(t.TempDir(), "call.mp3")
+	if err := os.WriteFile(path, []byte("local audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := c.run("transcribe", path, "-j")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	var result S.TranscriptResponse
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %s.", out)
+	}
+	if *result.Status != "completed" || !strings.HasPrefix(*result.Text, "Hello and welcome") {
+		t.Errorf("Expected the completed transcript, got %s.", out)
+	}
+	if string(c.server.Upload(*result.AudioURL)) != "local audio" {
+		t.Errorf("Expected the file to be uploaded to %s.", *result.AudioURL)
+	}
+}
+
+func TestGetFormatted(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+
+	out, code := c.run("get", "tr_done")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	if !strings.Contains(out, "00:00\tHello and welcome to the show.") {
+		t.Errorf("Expected the formatted transcript, got %s.", out)
+	}
+}
+
+func TestTranscriptionFailed(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.SetStatuses("queued", "error")
+
+	out, code := c.run("transcribe", c.server.AudioURL("broken.mp3"))
+	if code != U.ExitError {
+		t.Errorf("Expected exit code %d, got %d.", U.ExitError, code)
+	}
+	if out != "\nTranscoding failed.\n" {
+		t.Errorf("Expected the transcript error, got %s.", out)
+	}
+}
+
+func TestAPIErrorExitCodes(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+
+	c.server.Fail("POST", "/v2/transcript", fakeapi.Failure{Status: 400, Message: "Invalid audio_url"})
+	out, code := c.run("transcribe", c.server.AudioURL("a.mp3"))
+	if code != U.ExitInvalidRequest {
+		t.Errorf("Expected exit code %d, got %d.", U.ExitInvalidRequest, code)
+	}
+	if !strings.Contains(out, "The API responded with 400: Invalid audio_url (request ID fake-request)") {
+		t.Errorf("Expected the API error message, got %s.", out)
+	}
+
+	c.server.Fail("GET", "/v2/transcript/missing", fakeapi.Failure{Status: 500})
+	if _, code := c.run("get", "missing", "--max-retries=0"); code != U.ExitServerError {
+		t.Errorf("Expected exit code %d, got %d.", U.ExitServerError, code)
+	}
+
+	if _, code := newCLI(t).run("get", "tr_1"); code != U.ExitAuthentication {
+		t.Errorf("Expected exit code %d without a token, got %d.", U.ExitAuthentication, code)
+	}
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+	c.server.Fail("GET", "/v2/transcript/tr_done", fakeapi.Failure{Status: 502})
+
+	if out, code := c.run("get", "tr_done", "-j"); code != 0 {
+		t.Errorf("Expected the request to be retried, got exit code %d: %s", code, out)
+	}
+}
+
+func TestList(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_old", map[string]interface{}{"created": "2023-01-10T10:00:00.000000", "audio_url": "https://example.com/old.mp3"})
+	c.server.AddTranscript("tr_failed", map[string]interface{}{"created": "2023-02-10T10:00:00.000000", "status": "error", "audio_url": "https://example.com/failed.mp3"})
+	c.server.AddTranscript("tr_new", map[string]interface{}{"created": "2023-03-10T10:00:00.000000", "audio_url": "https://example.com/new.mp3", "audio_duration": 75})
+
+	out, _ := c.run("list", "--ids-only")
+	if out != "tr_new\ntr_failed\ntr_old\n" {
+		t.Errorf("Expected all IDs newest first, got %s.", out)
+	}
+
+	out, _ = c.run("list", "--ids-only", "--limit", "0", "--after", "2023-02-01", "--before", "2023-03-01")
+	if out != "tr_failed\n" {
+		t.Errorf("Expected the transcript created in February, got %s.", out)
+	}
+
+	out, _ = c.run("list", "--ids-only", "--status", "completed", "-n", "1")
+	if out != "tr_new\n" {
+		t.Errorf("Expected the newest completed transcript, got %s.", out)
+	}
+
+	out, _ = c.run("list")
+	if !strings.Contains(out, "| tr_new    |\tcompleted |\t2023-03-10 10:00 |\t01:15    |\thttps://example.com/new.mp3") {
+		t.Errorf("Expected a table of transcripts, got %s.", out)
+	}
+	c.env = []string{"TZ=Asia/Tokyo"}
+	if out, _ = c.run("list"); !strings.Contains(out, "| tr_new    |\tcompleted |\t2023-03-10 19:00 |") {
+		t.Errorf("Expected the creation times in the local time zone, got %s.", out)
+	}
+	c.env = nil
+
+	out, _ = c.run("list", "-j")
+	var items []S.TranscriptListItem
+	if err := json.Unmarshal([]byte(out), &items); err != nil || len(items) != 3 {
+		t.Errorf("Expected a JSON array of 3 transcripts, got %s.", out)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_old", map[string]interface{}{"created": "2020-01-10T10:00:00.000000"})
+	c.server.AddTranscript("tr_new", nil)
+
+	out, _ := c.run("delete", "--older-than", "30d", "--dry-run")
+	if out != "Would delete 1 transcript(s):\ntr_old\n" {
+		t.Errorf("Expected a dry run listing the old transcript, got %s.", out)
+	}
+	if out, _ := c.run("delete", "--older-than", "30d"); !strings.HasSuffix(out, "Nothing was deleted.\n") {
+		t.Errorf("Expected --older-than not to read IDs from stdin, got %s.", out)
+	}
+	if out, _ := c.run("delete", "tr_new"); !strings.HasSuffix(out, "Nothing was deleted.\n") {
+		t.Errorf("Expected the deletion to need confirmation, got %s.", out)
+	}
+	if len(c.server.Deleted()) != 0 {
+		t.Fatalf("Expected nothing to be deleted yet, got %v.", c.server.Deleted())
+	}
+
+	ids := filepath.Join(t.TempDir(), "ids.txt")
+	os.WriteFile(ids, []byte("# old transcripts\ntr_old\ntr_missing\n"), 0644)
+	out, code := c.run("delete", "tr_new", "tr_old", "--file", ids, "--yes")
+	if code != U.ExitInvalidRequest {
+		t.Errorf("Expected exit code %d for the missing transcript, got %d.", U.ExitInvalidRequest, code)
+	}
+	if !strings.Contains(out, "| tr_missing |\tfailed: Transcript lookup error, transcript id not found") {
+		t.Errorf("Expected the failure to be reported per ID, got %s.", out)
+	}
+	if !strings.Contains(out, "Deleted 2 of 3 transcript(s).") {
+		t.Errorf("Expected a summary, got %s.", out)
+	}
+	if strings.Join(c.server.Deleted(), ",") != "tr_new,tr_old" {
+		t.Errorf("Expected tr_new and tr_old to be deleted, got %v.", c.server.Deleted())
+	}
+}
+
+func TestGetOutputFormats(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+
+	out, code := c.run("get", "tr_done", "--format", "csv")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	if !strings.HasPrefix(out, "start,end,speaker,confidence,text\n") {
+		t.Errorf("Expected only CSV on stdout, got %s.", out)
+	}
+
+	dir := filepath.Join(t.TempDir(), "captions") + string(os.PathSeparator)
+	markdown := filepath.Join(t.TempDir(), "notes.md")
+	out, code = c.run("get", "tr_done", "--format", "srt,vtt", "--format", "markdown="+markdown, "-o", dir)
+	if code != 0 || out != "" {
+		t.Fatalf("Expected nothing on stdout, got %d: %s", code, out)
+	}
+	for _, path := range []string{filepath.Join(dir, "tr_done.srt"), filepath.Join(dir, "tr_done.vtt"), markdown} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be written: %v", path, err)
+		}
+	}
+	fetches := 0
+	for _, request := range c.server.Requests() {
+		if request == "GET /v2/transcript/tr_done" {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the transcript to be fetched once, then read from the history, got %d fetches.", fetches)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(blocker, nil, 0644)
+	for _, output := range []string{filepath.Join(blocker, "notes.md"), filepath.Join(blocker, "captions") + string(os.PathSeparator)} {
+		if out, code := c.run("get", "tr_done", "--format", "srt", "-o", output); code != U.ExitError || !strings.Contains(out, "please check your permissions") {
+			t.Errorf("Expected a file that can't be written to %s to fail, got %d: %s", output, code, out)
+		}
+	}
+
+	out, code = c.run("get", "tr_done", "--format", "text,json")
+	if code != U.ExitError || !strings.Contains(out, "only one format can be written to stdout") {
+		t.Errorf("Expected two formats on stdout to be rejected, got %d: %s", code, out)
+	}
+	out, code = c.run("get", "tr_done", "--format", "docx")
+	if code != U.ExitError || !strings.Contains(out, "unknown format \"docx\"") {
+		t.Errorf("Expected an unknown format to be rejected, got %d: %s", code, out)
+	}
+}
+
+func TestTranscribeBatch(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.SetStatuses("queued", "completed")
+	dir := t.TempDir()
+	for _, name := range []string{"calls/a.mp3", "calls/b.wav", "calls/notes.txt"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+	manifest := filepath.Join(dir, "jobs.jsonl")
+	os.WriteFile(manifest, []byte(`{"path": "`+c.server.AudioURL("remote.mp3")+`", "language_code": "es"}`+"\n"), 0644)
+	output := filepath.Join(dir, "out")
+
+	out, code := c.run("transcribe", filepath.Join(dir, "calls"), "--manifest", manifest, "--format", "text,json", "-j", "--output", output, "--concurrency", "2")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	if !strings.Contains(out, "Transcribed 3 of 3 file(s).") {
+		t.Errorf("Expected a summary, got %s.", out)
+	}
+	for _, name := range []string{"a.txt", "a.json", "b.txt", "b.json", "remote.json"} {
+		if _, err := os.Stat(filepath.Join(output, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+	var remote S.TranscriptResponse
+	data, _ := os.ReadFile(filepath.Join(output, "remote.json"))
+	if err := json.Unmarshal(data, &remote); err != nil || remote.LanguageCode == nil || *remote.LanguageCode != "es" {
+		t.Errorf("Expected the manifest to override the language code, got %s.", data)
+	}
+
+	os.WriteFile(manifest, []byte(`{"path": "https://youtu.be/abc"}`+"\n"), 0644)
+	out, code = c.run("transcribe", filepath.Join(dir, "calls", "a.mp3"), "--manifest", manifest, "--output", output)
+	if code != U.ExitError {
+		t.Errorf("Expected exit code %d, got %d.", U.ExitError, code)
+	}
+	if !strings.Contains(out, "Transcribed 1 of 2 file(s).") || !strings.Contains(out, "YouTube links can't be transcribed in a batch") {
+		t.Errorf("Expected the failure in the summary, got %s.", out)
+	}
+}
+
+func TestBatchResume(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.SetStatuses("queued", "completed")
+	dir := t.TempDir()
+	for _, name := range []string{"a.mp3", "b.mp3", "c.mp3"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+	output := filepath.Join(dir, "out")
+	count := func(request string) int {
+		n := 0
+		for _, r := range c.server.Requests() {
+			if r == request {
+				n++
+			}
+		}
+		return n
+	}
+
+	c.server.Fail("POST", "/v2/upload", fakeapi.Failure{Status: 400, Message: "Upload rejected"})
+	webhook := []string{"--webhook_url", "https://example.com/hook", "--webhook_auth_header_name", "X-Secret", "--webhook_auth_header_value", "s3cret"}
+	out, code := c.run(append([]string{"transcribe", filepath.Join(dir, "*.mp3"), "--concurrency", "1", "-p=false", "--output", output, "--format", "text,json"}, webhook...)...)
+	if code != U.ExitInvalidRequest || !strings.Contains(out, "Submitted 2 of 3 file(s).") {
+		t.Fatalf("Expected the first upload to fail, got %d: %s", code, out)
+	}
+	journals, _ := filepath.Glob(filepath.Join(c.home, ".config", "assemblyai", "jobs", "*.json"))
+	if len(journals) != 1 {
+		t.Fatalf("Expected a journal, got %v", journals)
+	}
+	if data, _ := os.ReadFile(journals[0]); strings.Contains(string(data), "s3cret") {
+		t.Errorf("Expected the webhook header value to be kept out of the journal, got %s", data)
+	}
+	job := strings.TrimSuffix(filepath.Base(journals[0]), ".json")
+	if out, _ := c.run("batch", "list"); !strings.Contains(out, "| "+job+" |") || !strings.Contains(out, "0/3  |\t1") {
+		t.Errorf("Expected the batch to be listed, got %s.", out)
+	}
+
+	if out, code := c.run("batch", "resume", job); code != U.ExitError || !strings.Contains(out, "Pass it again with --webhook_auth_header_value") {
+		t.Errorf("Expected the webhook header value to be required, got %d: %s", code, out)
+	}
+	out, code = c.run("batch", "resume", job, "--webhook_auth_header_value", "s3cret")
+	if code != 0 || !strings.Contains(out, "Transcribed 3 of 3 file(s).") {
+		t.Fatalf("Expected the batch to complete, got %d: %s", code, out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(output, "a.json")); !strings.Contains(string(data), `"webhook_auth_header_value": "s3cret"`) {
+		t.Errorf("Expected the webhook header value to be sent again, got %s", data)
+	}
+	if uploads, submits := count("POST /v2/upload"), count("POST /v2/transcript"); uploads != 4 || submits != 3 {
+		t.Errorf("Expected only the failed file to be uploaded and submitted again, got %d uploads and %d submits.", uploads, submits)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if _, err := os.Stat(filepath.Join(output, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+
+	fetches := count("GET /v2/transcript/tr_2")
+	out, code = c.run("batch", "resume", job)
+	if code != 0 || strings.Count(out, "completed") != 3 || count("GET /v2/transcript/tr_2") != fetches || count("POST /v2/transcript") != 3 {
+		t.Errorf("Expected a finished batch to be skipped without any request, got %d: %s", code, out)
+	}
+	if _, code := c.run("batch", "resume", "missing"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for a missing batch, got %d.", U.ExitError, code)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	path := filepath.Join(t.TempDir(), "call.mp3")
+	os.WriteFile(path, []byte("local audio"), 0644)
+	fetches := func(id string) int {
+		n := 0
+		for _, request := range c.server.Requests() {
+			if request == "GET /v2/transcript/"+id {
+				n++
+			}
+		}
+		return n
+	}
+
+	if out, code := c.run("transcribe", path, "-p=false"); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	out, _ := c.run("history")
+	if !strings.Contains(out, "| tr_2 |\tqueued |") || !strings.Contains(out, path) {
+		t.Errorf("Expected the submitted file in the history, got %s.", out)
+	}
+
+	c.server.SetStatuses("queued", "completed")
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+	if _, code := c.run("get", "tr_done"); code != 0 || fetches("tr_done") != 1 {
+		t.Fatalf("Expected the transcript to be fetched, got %d fetches.", fetches("tr_done"))
+	}
+	requests := len(c.server.Requests())
+	out, code := c.run("get", "tr_done", "-j")
+	if code != 0 || len(c.server.Requests()) != requests || !strings.Contains(out, `"id": "tr_done"`) {
+		t.Errorf("Expected the completed transcript to come from the history without requests, got %v: %s", c.server.Requests()[requests:], out)
+	}
+	if _, code := c.run("get", "tr_done", "--refresh"); code != 0 || fetches("tr_done") != 2 {
+		t.Errorf("Expected --refresh to fetch the transcript again, got %d fetches.", fetches("tr_done"))
+	}
+	if _, code := c.run("get", "tr_done", "--profile", "staging"); code != U.ExitError || fetches("tr_done") != 2 {
+		t.Errorf("Expected a profile without a token not to read the history, got %d with %d fetches.", code, fetches("tr_done"))
+	}
+	c.run("config", "--profile", "staging", fakeToken)
+	if _, code := c.run("get", "tr_done", "--profile", "staging"); code != 0 || fetches("tr_done") != 3 {
+		t.Errorf("Expected the history of another profile not to be used, got %d fetches.", fetches("tr_done"))
+	}
+
+	var entries []S.HistoryEntry
+	out, _ = c.run("history", "--json", "--status", "completed")
+	if err := json.Unmarshal([]byte(out), &entries); err != nil || len(entries) != 1 || entries[0].ID != "tr_done" {
+		t.Errorf("Expected the completed transcript only, got %s.", out)
+	}
+
+	c.run("delete", "tr_done", "--yes")
+	if out, _ := c.run("history", "--status", "deleted"); !strings.Contains(out, "| tr_done |\tdeleted |") {
+		t.Errorf("Expected the transcript to be marked as deleted, got %s.", out)
+	}
+	c.run("get", "tr_done")
+	if fetches("tr_done") != 4 {
+		t.Errorf("Expected a deleted transcript not to be served from the history, got %d fetches.", fetches("tr_done"))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+
+	out, code := c.run("search", "recognition")
+	if code != 0 || out != "" {
+		t.Errorf("Expected no matches in an empty history, got %d: %s", code, out)
+	}
+
+	c.server.SetStatuses("queued", "completed")
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+	if _, code := c.run("get", "tr_done"); code != 0 {
+		t.Fatalf("Expected the transcript to be fetched, got exit code %d.", code)
+	}
+	requests := len(c.server.Requests())
+
+	out, code = c.run("search", `"speech recognition"`, "-C", "1")
+	if code != 0 || !strings.Contains(out, "| tr_done |\t00:05 |\tA       |\t...about speech recognition. Thanks...") {
+		t.Errorf("Expected the phrase with its context, got %d: %s", code, out)
+	}
+	out, _ = c.run("search", "great", "OR", "welcome", "--ids-only")
+	if out != "tr_done\n" {
+		t.Errorf("Expected the transcript ID once, got %q.", out)
+	}
+	var hits []S.SearchHit
+	out, _ = c.run("search", "thanks", "--json")
+	if err := json.Unmarshal([]byte(out), &hits); err != nil || len(hits) != 1 || hits[0].Speaker != "B" || hits[0].Start != 6000 {
+		t.Errorf("Expected one hit of speaker B, got %s.", out)
+	}
+	if len(c.server.Requests()) != requests {
+		t.Errorf("Expected search not to call the API, got %v.", c.server.Requests()[requests:])
+	}
+
+	if _, code := c.run("search", `"speech`); code != U.ExitError {
+		t.Errorf("Expected an invalid query to fail, got exit code %d.", code)
 	}
-	if string(out) != "\nPlease provide either language detection or language code, not both.\n" {
-		t.Errorf("Expected Please provide either language detection or language code, not both., got %s.", string(out))
+}
+
+func TestWords(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+
+	out, code := c.run("words", "tr_done", "--terms", "to,speech recognition,refund")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	for _, want := range []string{
+		"| 2     |\tto                 |\t00:01, 00:09",
+		"| 1     |\tspeech recognition |\t00:05",
+		"| 0     |\trefund             |\t",
+		"Found 3 match(es) in transcription tr_done.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output, got %s.", want, out)
+		}
+	}
+
+	var result S.WordSearchResponse
+	out, _ = c.run("words", "tr_done", "-t", "Thanks", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || len(result.Matches) != 1 || result.Matches[0].Timestamps[0] != [2]int64{6000, 6400} {
+		t.Errorf("Expected the match as JSON, got %s.", out)
+	}
+
+	if _, code := c.run("words", "tr_done"); code != U.ExitError {
+		t.Errorf("Expected exit code %d without terms, got %d.", U.ExitError, code)
+	}
+	if _, code := c.run("words", "tr_missing", "-t", "to"); code != U.ExitInvalidRequest {
+		t.Errorf("Expected exit code %d for a missing transcript, got %d.", U.ExitInvalidRequest, code)
+	}
+}
+
+func TestGetSegments(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+	audioURL := c.server.AudioURL("call.mp3")
+	requests := func(path string) int {
+		n := 0
+		for _, request := range c.server.Requests() {
+			if request == "GET "+path {
+				n++
+			}
+		}
+		return n
+	}
+
+	out, code := c.run("get", "tr_done", "--sentences")
+	if code != 0 || !strings.Contains(out, "[00:03 - 00:05]\tSpeaker A:\tToday we talk about speech recognition.\n[00:06 - 00:07]\tSpeaker B:\tThanks for having me.") {
+		t.Errorf("Expected one row per sentence, got %d: %s", code, out)
+	}
+	out, code = c.run("get", "tr_done", "--paragraphs")
+	if code != 0 || !strings.Contains(out, "[00:00 - 00:05]\tSpeaker A:\tHello and welcome to the show. Today we talk about speech recognition.\n[00:06 - 00:10]\tSpeaker B:\tThanks") {
+		t.Errorf("Expected one row per paragraph, got %d: %s", code, out)
+	}
+	if requests("/v2/transcript/tr_done") != 1 || requests("/v2/transcript/tr_done/paragraphs") != 1 {
+		t.Errorf("Expected the paragraphs of the cached transcript to be fetched, got %v.", c.server.Requests())
+	}
+
+	if _, code := c.run("get", "tr_done", "--paragraphs", "--sentences"); code != U.ExitError {
+		t.Errorf("Expected --sentences and --paragraphs to be exclusive, got exit code %d.", code)
+	}
+	for _, args := range [][]string{{"get", "tr_done", "-j"}, {"get", "tr_done", "--format", "csv"}, {"transcribe", audioURL, "--format", "text,srt=" + t.TempDir()}, {"transcribe", audioURL, audioURL, "--format", "json"}} {
+		if out, code := c.run(append(args, "--sentences")...); code != U.ExitError || !strings.Contains(out, "--sentences only changes the text output") {
+			t.Errorf("Expected --sentences to be rejected with %v, got %d: %s", args, code, out)
+		}
+	}
+	c.server.Fail("GET", "/v2/transcript/tr_done/sentences", fakeapi.Failure{Status: 400, Message: "Transcript is not completed"})
+	if out, code := c.run("get", "tr_done", "--sentences"); code != U.ExitInvalidRequest || !strings.Contains(out, "Transcript is not completed") {
+		t.Errorf("Expected the API error, got %d: %s", code, out)
+	}
+}
+
+func TestRedactedAudio(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.SetStatuses("queued", "completed")
+
+	out, _ := c.run("transcribe", c.server.AudioURL("call.mp3"), "--redact_pii", "--redact_pii_audio", "--redact_pii_audio_quality", "wav", "--redact_pii_sub", "entity_name", "-p=false", "-j")
+	var result S.TranscriptResponse
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %s.", out)
+	}
+	if result.RedactPiiAudio == nil || !*result.RedactPiiAudio || result.RedactPiiAudioQuality != "wav" || result.RedactPiiSub != "entity_name" {
+		t.Errorf("Expected the redaction parameters to be submitted, got %s.", out)
+	}
+
+	output := filepath.Join(t.TempDir(), "redacted.wav")
+	if out, code := c.run("redacted-audio", *result.ID, "-o", output); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "audio" {
+		t.Errorf("Expected the redacted audio to be downloaded, got %q.", data)
+	}
+	out, _ = c.run("redacted-audio", *result.ID, "--url-only")
+	if out != c.server.AudioURL(*result.ID+".wav")+"\n" {
+		t.Errorf("Expected the URL of the redacted audio, got %s.", out)
+	}
+
+	c.server.AddTranscript("tr_plain", fakeapi.DefaultResult())
+	if out, code := c.run("redacted-audio", "tr_plain"); code != U.ExitError || !strings.Contains(out, "has no redacted audio") {
+		t.Errorf("Expected a transcript without redacted audio to fail, got %d: %s", code, out)
+	}
+	for _, args := range [][]string{
+		{"--redact_pii_audio"},
+		{"--redact_pii", "--redact_pii_audio_quality", "wav"},
+		{"--redact_pii", "--redact_pii_audio", "--redact_pii_audio_quality", "flac"},
+		{"--redact_pii", "--redact_pii_sub", "stars"},
+	} {
+		args = append([]string{"transcribe", c.server.AudioURL("call.mp3"), "-p=false"}, args...)
+		if out, code := c.run(args...); code != U.ExitError {
+			t.Errorf("%v: expected exit code %d, got %d: %s", args, U.ExitError, code, out)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	dir := t.TempDir()
+
+	// One second of 16kHz silence is sent in five messages, each transcribed
+	// by the fake as the next word of its sample transcript.
+	wav := []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x80\x3e\x00\x00\x00\x7d\x00\x00\x02\x00\x10\x00data\x00\x7d\x00\x00")
+	wav = append(wav, make([]byte, 32000)...)
+	wavPath := filepath.Join(dir, "speech.wav")
+	os.WriteFile(wavPath, wav, 0644)
+
+	out, code := c.run("stream", wavPath, "--realtime=false", "--word-boost", "AssemblyAI,speech recognition")
+	if code != 0 || out != "Hello and welcome to\nthe\n" {
+		t.Errorf("Expected the final transcripts, got %d: %q", code, out)
+	}
+	sessions := c.server.RealtimeSessions()
+	if len(sessions) != 1 || len(sessions[0].Audio) != 32000 || !sessions[0].Terminated {
+		t.Fatalf("Expected the whole file to be streamed and the session terminated, got %+v.", sessions)
+	}
+	if sessions[0].Query.Get("sample_rate") != "16000" || sessions[0].Query.Get("word_boost") != `["AssemblyAI","speech recognition"]` {
+		t.Errorf("Expected the sample rate and word boost to be sent, got %v.", sessions[0].Query)
+	}
+
+	// 8kHz raw PCM: 0.25s is one full message and one padded to 100ms.
+	rawPath := filepath.Join(dir, "speech.pcm")
+	os.WriteFile(rawPath, make([]byte, 4000), 0644)
+	out, _ = c.run("stream", rawPath, "--sample-rate", "8000", "--realtime=false", "-j")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var messages []S.RealtimeMessage
+	for _, line := range lines {
+		var message S.RealtimeMessage
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("Expected JSON lines, got %s.", out)
+		}
+		messages = append(messages, message)
+	}
+	if len(messages) != 3 || messages[0].MessageType != "PartialTranscript" || messages[1].Text != "Hello and" || messages[2].MessageType != "FinalTranscript" || messages[2].AudioEnd != 300 {
+		t.Errorf("Expected two partial transcripts and a final one, got %s.", out)
+	}
+	if sessions := c.server.RealtimeSessions(); sessions[1].Query.Get("sample_rate") != "8000" || len(sessions[1].Audio) != 4800 {
+		t.Errorf("Expected the raw audio at 8kHz, got %d bytes at %s.", len(sessions[1].Audio), sessions[1].Query.Get("sample_rate"))
+	}
+
+	if _, code := c.run("stream", wavPath, "--sample-rate", "8000"); code != U.ExitError {
+		t.Errorf("Expected a sample rate not matching the file to fail, got exit code %d.", code)
+	}
+}
+
+func TestStreamInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupts can't be sent to processes on Windows.")
+	}
+	c := newCLI(t)
+	c.configure(t)
+
+	// stdin stays open, so the CLI is blocked reading it when interrupted.
+	cmd := c.command("stream")
+	stdin, _ := cmd.StdinPipe()
+	defer stdin.Close()
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stdin.Write(make([]byte, 6400))
+	for start := time.Now(); len(c.server.RealtimeSessions()) == 0 || len(c.server.RealtimeSessions()[0].Audio) == 0; {
+		if time.Since(start) > 5*time.Second {
+			cmd.Process.Kill()
+			t.Fatal("Expected the audio to be streamed.")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cmd.Process.Signal(os.Interrupt)
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the stream to stop cleanly, got %v.", err)
+		}
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("Expected Ctrl+C to stop the stream while it waits for audio.")
+	}
+	if sessions := c.server.RealtimeSessions(); !sessions[0].Terminated {
+		t.Errorf("Expected the session to be terminated, got %+v.", sessions[0])
+	}
+}
+
+func TestStreamToken(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+
+	out, code := c.run("stream", "token", "--expires-in", "120")
+	if code != 0 || !strings.HasPrefix(out, "rt_token_") || !strings.HasSuffix(out, "_120\n") {
+		t.Errorf("Expected a temporary token, got %d: %s", code, out)
+	}
+	var token S.RealtimeToken
+	out, _ = c.run("stream", "token", "-j")
+	if err := json.Unmarshal([]byte(out), &token); err != nil || !strings.HasSuffix(token.Token, "_60") || token.ExpiresAt == nil {
+		t.Errorf("Expected the token with its expiry as JSON, got %s.", out)
+	}
+
+	requests := len(c.server.Requests())
+	for _, expiresIn := range []string{"59", "360001"} {
+		if out, code := c.run("stream", "token", "--expires-in", expiresIn); code != U.ExitError || !strings.Contains(out, "between 60 and 360000") {
+			t.Errorf("Expected --expires-in %s to be rejected, got %d: %s", expiresIn, code, out)
+		}
+	}
+	if len(c.server.Requests()) != requests {
+		t.Errorf("Expected an invalid expiry to be rejected before calling the API, got %v.", c.server.Requests()[requests:])
+	}
+}
+
+func TestLemur(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.SetStatuses("queued", "completed")
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+	dir := t.TempDir()
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+	if out, code := c.run("transcribe", filepath.Join(dir, "*.mp3"), "--output", filepath.Join(dir, "out")); code != 0 {
+		t.Fatalf("Expected the batch to complete, got %d: %s", code, out)
+	}
+	journals, _ := filepath.Glob(filepath.Join(c.home, ".config", "assemblyai", "jobs", "*.json"))
+	if len(journals) != 1 {
+		t.Fatalf("Expected a journal, got %v", journals)
+	}
+	job := strings.TrimSuffix(filepath.Base(journals[0]), ".json")
+
+	out, code := c.run("lemur", "summary", "tr_done", "--batch", job, "--answer-format", "TLDR", "--temperature", "0.5")
+	if code != 0 || !strings.Contains(out, "Summary of tr_done, tr_") || !strings.Contains(out, "\nThe speakers talk about speech recognition.") {
+		t.Errorf("Expected the summary as a table, got %d: %s", code, out)
+	}
+	requests := c.server.LemurRequests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected a LeMUR request, got %v.", requests)
+	}
+	params := requests[0].Params
+	if ids, _ := params["transcript_ids"].([]interface{}); len(ids) != 3 || params["answer_format"] != "TLDR" || params["temperature"] != 0.5 {
+		t.Errorf("Expected the transcripts of the batch and the options to be sent, got %v.", params)
+	}
+
+	questions := filepath.Join(dir, "questions.txt")
+	os.WriteFile(questions, []byte("# About the show\nWho is the guest?\n\nWhat is the topic?\n"), 0644)
+	out, code = c.run("lemur", "question-answer", "tr_done", "-q", "Is it a podcast?", "--questions-file", questions)
+	for _, want := range []string{"| Question |\tIs it a podcast?", "| Answer   |\tAnswer 1 for tr_done.", "| Question |\tWhat is the topic?", "| Answer   |\tAnswer 3 for tr_done."} {
+		if code != 0 || !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output, got %d: %s", want, code, out)
+		}
+	}
+	if _, ok := c.server.LemurRequests()[1].Params["temperature"]; ok {
+		t.Errorf("Expected the temperature to be left to the API, got %v.", c.server.LemurRequests()[1].Params)
+	}
+
+	var result S.LemurResponse
+	out, code = c.run("lemur", "task", "tr_done", "--prompt", "List the topics.", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || code != 0 || result.Response != `Response to "List the topics." for tr_done.` || result.Usage.InputTokens != 100 {
+		t.Errorf("Expected the response as JSON, got %d: %s", code, out)
+	}
+	if out, code := c.run("lemur", "action-items", "tr_done"); code != 0 || !strings.Contains(out, "\n- Follow up on speech recognition.") {
+		t.Errorf("Expected the action items as a table, got %d: %s", code, out)
+	}
+
+	sent := len(c.server.LemurRequests())
+	for _, args := range [][]string{
+		{"lemur", "task", "tr_done"},
+		{"lemur", "summary"},
+		{"lemur", "summary", "--batch", "missing"},
+		{"lemur", "question-answer", "tr_done"},
+		{"lemur", "summary", "tr_done", "--temperature", "2"},
+	} {
+		if _, code := c.run(args...); code != U.ExitError {
+			t.Errorf("Expected exit code %d for %v, got %d.", U.ExitError, args, code)
+		}
+	}
+	if len(c.server.LemurRequests()) != sent {
+		t.Errorf("Expected invalid commands to fail before calling the API, got %v.", c.server.LemurRequests())
+	}
+	if _, code := c.run("lemur", "summary", "tr_missing"); code != U.ExitInvalidRequest {
+		t.Errorf("Expected exit code %d for a missing transcript, got %d.", U.ExitInvalidRequest, code)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	staging := fakeapi.New("staging-token")
+	t.Cleanup(staging.Close)
+	audioURL := c.server.AudioURL("call.mp3")
+
+	out, code := c.run("config", "--profile", "staging", "staging-token", "--base-url", staging.URL, "--default", "speaker_labels=true", "--default", "language_code=es")
+	if code != 0 || out != "You're now authenticated in the staging profile.\n" {
+		t.Fatalf("Expected the staging profile to be configured, got %d: %s", code, out)
+	}
+	if _, code := c.run("config", "--profile", "staging", "staging-token", "--default", "speaker_labels=maybe"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for an invalid default, got %d.", U.ExitError, code)
+	}
+	if _, code := c.run("config", "--profile", "Staging!", "staging-token"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for an invalid profile name, got %d.", U.ExitError, code)
+	}
+
+	// The base URL of the environment would override the one of the profile.
+	c.env = []string{"ASSEMBLYAI_BASE_URL="}
+	var result S.TranscriptResponse
+	out, _ = c.run("--profile", "staging", "transcribe", audioURL, "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.SpeakerLabels || *result.LanguageCode != "es" {
+		t.Fatalf("Expected the defaults of the profile to be sent, got %s.", out)
+	}
+	out, _ = c.run("--profile", "staging", "transcribe", audioURL, "-p=false", "-j", "--speaker_labels=false", "--dual_channel")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || result.SpeakerLabels {
+		t.Errorf("Expected flags to override the defaults of the profile, got %s.", out)
+	}
+	c.env = []string{"ASSEMBLYAI_BASE_URL=", "ASSEMBLYAI_PROFILE=staging"}
+	if out, code := c.run("transcribe", audioURL, "-p=false", "-j"); code != 0 {
+		t.Errorf("Expected ASSEMBLYAI_PROFILE to select the profile, got %d: %s", code, out)
+	}
+	c.env = nil
+	if strings.Count(strings.Join(staging.Requests(), ","), "POST /v2/transcript") != 3 || U.Contains(c.server.Requests(), "POST /v2/transcript") {
+		t.Errorf("Expected the staging profile to only use its base URL, got %v and %v.", staging.Requests(), c.server.Requests())
+	}
+
+	out, _ = c.run("config", "profiles", "list")
+	if !strings.Contains(out, "| default |\t*") || !strings.Contains(out, "| staging |\t       |\t"+staging.URL) {
+		t.Errorf("Expected the profiles to be listed, got %s.", out)
+	}
+	if out, code := c.run("config", "profiles", "use", "staging"); code != 0 || !strings.Contains(out, "Now using the staging profile.") {
+		t.Errorf("Expected the staging profile to be used, got %d: %s", code, out)
+	}
+	if out, _ := c.run("config", "profiles", "list"); !strings.Contains(out, "| staging |\t*") {
+		t.Errorf("Expected the staging profile to be active, got %s.", out)
+	}
+	if out, code := c.run("--profile", "missing", "get", "tr_1"); code != U.ExitError || !strings.Contains(out, "the profile missing doesn't exist") {
+		t.Errorf("Expected a missing profile to fail, got %d: %s", code, out)
+	}
+
+	if _, code := c.run("config", "profiles", "remove", "default"); code != U.ExitError {
+		t.Errorf("Expected exit code %d when removing the default profile, got %d.", U.ExitError, code)
+	}
+	if out, code := c.run("config", "profiles", "remove", "staging"); code != 0 || !strings.Contains(out, "Removed the staging profile.") {
+		t.Errorf("Expected the staging profile to be removed, got %d: %s", code, out)
+	}
+	if out, _ := c.run("config", "profiles", "list"); strings.Contains(out, "staging") || !strings.Contains(out, "| default |\t*") {
+		t.Errorf("Expected only the default profile to be left, got %s.", out)
+	}
+	if out, code := c.run("words", "tr_missing", "-t", "to"); code != U.ExitInvalidRequest {
+		t.Errorf("Expected the default profile to still be configured, got %d: %s", code, out)
+	}
+
+	config, _ := os.OpenFile(filepath.Join(c.home, ".config", "assemblyai", "config.toml"), os.O_APPEND|os.O_WRONLY, 0)
+	config.WriteString("\n[transcribe]\nspeaker_labels = \"maybe\"\n")
+	config.Close()
+	if out, code := c.run("transcribe", audioURL, "-p=false"); code != U.ExitError || !strings.Contains(out, `Invalid default speaker_labels = "maybe" in the config file.`) {
+		t.Errorf("Expected the invalid default of the config file to be reported, got %d: %s", code, out)
+	}
+}
+
+func TestTokenSources(t *testing.T) {
+	c := newCLI(t)
+	c.env = []string{"ASSEMBLYAI_API_KEY=" + fakeToken}
+	if out, code := c.run("words", "tr_missing", "-t", "to"); code != U.ExitInvalidRequest {
+		t.Errorf("Expected ASSEMBLYAI_API_KEY to authenticate without a config file, got %d: %s", code, out)
+	}
+	if out, _ := c.run("validate"); out != "Your Token is fake**oken, from the ASSEMBLYAI_API_KEY environment variable.\n" {
+		t.Errorf("Expected the masked token, got %s.", out)
+	}
+	c.env = []string{"ASSEMBLYAI_API_KEY=invalid"}
+	if out, code := c.run("words", "tr_missing", "-t", "to", "--token", fakeToken); code != U.ExitInvalidRequest {
+		t.Errorf("Expected --token to take precedence over ASSEMBLYAI_API_KEY, got %d: %s", code, out)
+	}
+
+	c.env = []string{"ASSEMBLYAI_KEYRING_PASSWORD=secret"}
+	if out, code := c.run("config", fakeToken, "--token-storage", "encrypted-file"); code != 0 {
+		t.Fatalf("Expected the token to be stored in the encrypted file, got %d: %s", code, out)
+	}
+	for _, name := range []string{"config.toml", "credentials.enc"} {
+		data, err := os.ReadFile(filepath.Join(c.home, ".config", "assemblyai", name))
+		if err != nil || strings.Contains(string(data), fakeToken) {
+			t.Errorf("Expected %s to hold no plain text token, got %v: %s", name, err, data)
+		}
+	}
+	if out, _ := c.run("validate"); out != "Your Token is fake**oken, from the encrypted token file.\n" {
+		t.Errorf("Expected the token of the encrypted file, got %s.", out)
+	}
+	c.env = []string{"ASSEMBLYAI_KEYRING_PASSWORD=wrong"}
+	if out, code := c.run("words", "tr_missing", "-t", "to"); code != U.ExitError || !strings.Contains(out, "wrong password") {
+		t.Errorf("Expected a wrong password to fail, got %d: %s", code, out)
+	}
+	c.env = nil
+	if out, code := c.run("words", "tr_missing", "-t", "to"); code != U.ExitError || !strings.Contains(out, "ASSEMBLYAI_KEYRING_PASSWORD") {
+		t.Errorf("Expected a missing password to fail, got %d: %s", code, out)
+	}
+
+	if _, code := c.run("config", fakeToken, "--token-storage", "clipboard"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for an unknown storage, got %d.", U.ExitError, code)
+	}
+	c.configure(t)
+	if out, _ := c.run("validate"); out != "Your Token is fake**oken, from the config file.\n" {
+		t.Errorf("Expected the token of the config file, got %s.", out)
+	}
+}
+
+func TestConfigSettings(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	c.server.AddTranscript("tr_done", fakeapi.DefaultResult())
+
+	if out, code := c.run("config", "set", "features.telemetry", "0"); code != 0 || out != "Set features.telemetry to false.\n" {
+		t.Errorf("Expected the setting to be stored, got %d: %s", code, out)
+	}
+	if out, _ := c.run("config", "get", "features.telemetry"); out != "false\n" {
+		t.Errorf("Expected the stored value, got %s.", out)
+	}
+	c.configure(t)
+	if out, _ := c.run("config", "get", "features.telemetry"); out != "false\n" {
+		t.Errorf("Expected configuring the token again to keep the setting, got %s.", out)
+	}
+	if out, _ := c.run("config", "get", "api.read_timeout"); out != "1m0s\n" {
+		t.Errorf("Expected the default value, got %s.", out)
+	}
+
+	for _, args := range [][]string{
+		{"config", "set", "telemetry", "false"},
+		{"config", "set", "features.telemetry", "maybe"},
+		{"config", "set", "api.read_timeout", "2"},
+		{"config", "set", "api.max_retries", "many"},
+		{"config", "set", "api.base_url", "localhost"},
+		{"config", "set", "output.format", "docx"},
+		{"config", "set", "transcribe.speaker_labels", "yes please"},
+		{"config", "get", "unknown.key"},
+		{"config", "unset", "unknown.key"},
+	} {
+		if out, code := c.run(args...); code != U.ExitError || !strings.Contains(out, "Invalid setting:") {
+			t.Errorf("Expected %v to be rejected, got %d: %s", args, code, out)
+		}
+	}
+
+	c.run("config", "set", "output.format", "srt")
+	if out, code := c.run("get", "tr_done"); code != 0 || !strings.Contains(out, "00:00:00,000 --> ") {
+		t.Errorf("Expected output.format to be the default format, got %d: %s", code, out)
+	}
+	c.run("config", "set", "transcribe.speaker_labels", "true")
+	var result S.TranscriptResponse
+	out, _ := c.run("transcribe", c.server.AudioURL("call.mp3"), "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.SpeakerLabels {
+		t.Errorf("Expected transcribe.speaker_labels to be sent, got %s.", out)
+	}
+
+	out, _ = c.run("config", "list")
+	for _, want := range []string{"| output.format ", "srt ", "config file", "| api.read_timeout ", "default"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the settings, got %s.", want, out)
+		}
+	}
+	var values []S.ConfigValue
+	out, _ = c.run("config", "list", "-j", "--read-timeout", "5s")
+	if err := json.Unmarshal([]byte(out), &values); err != nil {
+		t.Fatalf("Expected the settings as JSON, got %s.", out)
+	}
+	for _, value := range values {
+		if value.Key == "api.read_timeout" && (value.Value != "5s" || value.Source != "flag") {
+			t.Errorf("Expected the flag to be reported as the source, got %+v.", value)
+		}
+	}
+
+	if out, code := c.run("config", "unset", "output.format"); code != 0 || out != "Unset output.format.\n" {
+		t.Errorf("Expected the setting to be removed, got %d: %s", code, out)
+	}
+	if out, _ := c.run("config", "unset", "output.format"); out != "output.format isn't set.\n" {
+		t.Errorf("Expected the setting to be reported as not set, got %s.", out)
+	}
+	if out, _ := c.run("config", "get", "output.format"); out != "text\n" {
+		t.Errorf("Expected the default format, got %s.", out)
+	}
+	if out, _ := c.run("validate"); !strings.Contains(out, "from the config file") {
+		t.Errorf("Expected the other settings to be kept, got %s.", out)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	audioURL := c.server.AudioURL("call.mp3")
+
+	out, code := c.run("preset", "save", "calls", "-l", "-x", "-r", "-i", "person_name,phone_number", "--language_code", "es")
+	if code != 0 || !strings.Contains(out, "Saved the calls preset.") {
+		t.Fatalf("Expected the preset to be saved, got %d: %s", code, out)
+	}
+	if _, code := c.run("preset", "save", "empty"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for a preset without flags, got %d.", U.ExitError, code)
+	}
+
+	var result S.TranscriptResponse
+	out, _ = c.run("transcribe", audioURL, "--preset", "calls", "--language_code", "fr", "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %s.", out)
+	}
+	if !result.SpeakerLabels || !*result.SentimentAnalysis || !*result.RedactPii || *result.LanguageCode != "fr" {
+		t.Errorf("Expected the preset with the explicit language code, got %s.", out)
+	}
+	if policies := fmt.Sprint(result.RedactPiiPolicies); policies != "[person_name phone_number]" {
+		t.Errorf("Expected the policies of the preset, got %s.", policies)
+	}
+	if _, code := c.run("transcribe", audioURL, "--preset", "missing", "-p=false"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for a missing preset, got %d.", U.ExitError, code)
+	}
+
+	var preset S.Preset
+	out, _ = c.run("preset", "show", "calls", "-j")
+	if err := json.Unmarshal([]byte(out), &preset); err != nil || preset.Flags["redact_pii_policies"] != "person_name,phone_number" || preset.Source != "config" {
+		t.Errorf("Expected the preset as JSON, got %s.", out)
+	}
+	if out, _ := c.run("preset", "list"); !strings.Contains(out, "| calls |\tconfig |\t--language_code=es --redact_pii=true") {
+		t.Errorf("Expected the preset to be listed, got %s.", out)
+	}
+
+	c.dir = t.TempDir()
+	os.WriteFile(filepath.Join(c.dir, ".assemblyai.yaml"), []byte("presets:\n  podcast:\n    auto_chapters: true\n    redact_pii: true\n    redact_pii_policies: [person_name]\n  typo:\n    speaker_lables: true\n"), 0644)
+	out, _ = c.run("transcribe", audioURL, "--preset", "podcast", "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !*result.AutoChapters || fmt.Sprint(result.RedactPiiPolicies) != "[person_name]" {
+		t.Errorf("Expected the preset of the project file, got %s.", out)
+	}
+	if out, code := c.run("transcribe", audioURL, "--preset", "typo", "-p=false"); code != U.ExitError || !strings.Contains(out, "transcribe has no --speaker_lables flag") {
+		t.Errorf("Expected an unknown flag in a preset to be reported, got %d: %s", code, out)
+	}
+	if out, _ := c.run("preset", "list"); !strings.Contains(out, "| calls   |\tconfig ") || !strings.Contains(out, "| podcast |\tproject |\t--auto_chapters=true --redact_pii=true --redact_pii_policies=person_name") {
+		t.Errorf("Expected the presets of both files, got %s.", out)
+	}
+	if out, code := c.run("preset", "delete", "podcast"); code != U.ExitError || !strings.Contains(out, "edit it there") {
+		t.Errorf("Expected presets of the project file to be kept, got %d: %s", code, out)
+	}
+	os.WriteFile(filepath.Join(c.dir, ".assemblyai.yaml"), []byte("presets:\n  broken:\n    auto_chapters: maybe\n"), 0644)
+	if out, code := c.run("transcribe", audioURL, "--preset", "broken", "-p=false"); code != U.ExitError || !strings.Contains(out, "transcribe.auto_chapters expects true or false") {
+		t.Errorf("Expected an invalid value in a preset to be reported, got %d: %s", code, out)
+	}
+	c.dir = ""
+
+	if out, code := c.run("preset", "delete", "calls"); code != 0 || out != "Deleted the calls preset.\n" {
+		t.Errorf("Expected the preset to be deleted, got %d: %s", code, out)
+	}
+	if _, code := c.run("preset", "delete", "calls"); code != U.ExitError {
+		t.Errorf("Expected exit code %d for a missing preset, got %d.", U.ExitError, code)
+	}
+	if out, _ := c.run("validate"); !strings.Contains(out, "from the config file") {
+		t.Errorf("Expected the token to be kept, got %s.", out)
+	}
+}
+
+func TestProjectConfig(t *testing.T) {
+	c := newCLI(t)
+	c.configure(t)
+	audioURL := c.server.AudioURL("call.mp3")
+	c.run("config", "set", "transcribe.language_code", "fr")
+
+	root := t.TempDir()
+	c.dir = filepath.Join(root, "episodes", "2024")
+	os.MkdirAll(c.dir, 0755)
+	victim := filepath.Join(root, "victim.txt")
+	os.WriteFile(victim, []byte("keep me"), 0644)
+	project := filepath.Join(root, ".assemblyai.toml")
+	os.WriteFile(project, []byte(`[transcribe]
+language_code = "es"
+word_boost = ["AssemblyAI", "LeMUR"]
+custom_spelling = [{ from = ["assembly ai"], to = "AssemblyAI" }]
+webhook_url = "https://attacker.example.com/hook"
+output = "`+victim+`"
+
+[presets.hooked]
+speaker_labels = true
+webhook_url = "https://attacker.example.com/hook"
+format = "text=`+victim+`"
+
+[api]
+base_url = "http://127.0.0.1:1"
+
+[config]
+token = "project-token"
+`), 0644)
+
+	var result S.TranscriptResponse
+	out, code := c.run("transcribe", audioURL, "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || code != 0 {
+		t.Fatalf("Expected the project file to be used against the configured API, got %d: %s", code, out)
+	}
+	if *result.LanguageCode != "es" || fmt.Sprint(result.WordBoost) != "[AssemblyAI LeMUR]" {
+		t.Errorf("Expected the settings of the project file over the config file, got %s.", out)
+	}
+	if spelling, _ := json.Marshal(result.CustomSpelling); string(spelling) != `[{"from":["assembly ai"],"to":"AssemblyAI"}]` {
+		t.Errorf("Expected the custom spelling of the project file, got %s.", spelling)
+	}
+	if result.WebhookURL != nil {
+		t.Errorf("Expected the webhook of the project file to be ignored, got %v.", result.WebhookURL)
+	}
+	out, _ = c.run("transcribe", audioURL, "--preset", "hooked", "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.SpeakerLabels || result.WebhookURL != nil {
+		t.Errorf("Expected the webhook of a project preset to be ignored, got %s.", out)
+	}
+	if data, _ := os.ReadFile(victim); string(data) != "keep me" {
+		t.Errorf("Expected the output flags of the project file to be ignored, got %s.", data)
+	}
+
+	out, _ = c.run("transcribe", audioURL, "--language_code", "de", "-k", "CLI", "-p=false", "-j")
+	if err := json.Unmarshal([]byte(out), &result); err != nil || *result.LanguageCode != "de" || fmt.Sprint(result.WordBoost) != "[CLI]" {
+		t.Errorf("Expected flags to take precedence over the project file, got %s.", out)
+	}
+
+	if out, _ := c.run("config", "which", "transcribe.language_code"); out != "transcribe.language_code = es (from "+project+")\n" {
+		t.Errorf("Expected the project file as the source, got %s.", out)
+	}
+	if out, _ := c.run("config", "which", "api.base_url"); out != "api.base_url = "+c.server.URL+" (from ASSEMBLYAI_BASE_URL)\n" {
+		t.Errorf("Expected the api table of the project file to be ignored, got %s.", out)
+	}
+	if out, _ := c.run("config", "which"); !strings.HasPrefix(out, "Project file: "+project+"\nProfile: default\n") {
+		t.Errorf("Expected the project file and profile in use, got %s.", out)
+	}
+	var which S.ConfigWhich
+	out, _ = c.run("config", "which", "-j")
+	if err := json.Unmarshal([]byte(out), &which); err != nil || which.ProjectFile != project || len(which.Settings) == 0 {
+		t.Errorf("Expected the effective settings as JSON, got %s.", out)
+	}
+	if out, _ := c.run("validate"); !strings.Contains(out, "from the config file") {
+		t.Errorf("Expected the token of the project file to be ignored, got %s.", out)
+	}
+
+	os.WriteFile(filepath.Join(c.dir, ".assemblyai.yaml"), []byte("transcribe:\n  speaker_labels: maybe\n"), 0644)
+	if out, code := c.run("transcribe", audioURL, "-p=false"); code != U.ExitError || !strings.Contains(out, "Invalid project file") {
+		t.Errorf("Expected an invalid project file to be reported, got %d: %s", code, out)
+	}
+
+	c.dir = t.TempDir()
+	if out, _ := c.run("config", "which", "transcribe.language_code"); out != "transcribe.language_code = fr (from config file)\n" {
+		t.Errorf("Expected the config file without a project file, got %s.", out)
 	}
 }
@@ -5,6 +5,7 @@ package schemas
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type CheckIfTokenValidResponse struct {
@@ -76,6 +77,12 @@ type TranscriptResponse struct {
 	WebhookURL               interface{}                `json:"webhook_url"`
 	WordBoost                []interface{}              `json:"word_boost,omitempty"`
 	Words                    []SentimentAnalysisResult  `json:"words,omitempty"`
+
+	// Raw holds the response body exactly as returned by the API.
+	Raw json.RawMessage `json:"-"`
+	// Segments are the sentences or paragraphs of the transcript when they
+	// were fetched from the API.
+	Segments *[]SentimentAnalysisResult `json:"-"`
 }
 
 type AutoHighlightsResult struct {
@@ -176,10 +183,33 @@ type UploadResponse struct {
 	UploadURL string `json:"upload_url"`
 }
 
+// TranscribeFlags control how a transcript is shown. Segmentation is
+// "sentences" or "paragraphs" to show the transcript split by the API rather
+// than locally.
 type TranscribeFlags struct {
-	Poll bool `json:"poll"`
-	Json bool `json:"json"`
-	Srt  bool `json:"srt"`
+	Poll         bool           `json:"poll"`
+	Json         bool           `json:"json"`
+	Refresh      bool           `json:"refresh"`
+	Outputs      []OutputTarget `json:"outputs"`
+	Captions     CaptionOptions `json:"captions"`
+	Segmentation string         `json:"segmentation"`
+}
+
+// OutputTarget is one rendering of a transcript. An empty Path or "-" means
+// stdout, a directory gets an [id].[extension] file.
+type OutputTarget struct {
+	Format string `json:"format"`
+	Path   string `json:"path"`
+}
+
+// CaptionOptions controls how words are grouped into SRT and WebVTT cues. A
+// zero value disables the matching constraint.
+type CaptionOptions struct {
+	CharsPerLine    int           `json:"chars_per_line"`
+	LinesPerCaption int           `json:"lines_per_caption"`
+	MinDuration     time.Duration `json:"min_duration"`
+	MaxDuration     time.Duration `json:"max_duration"`
+	MaxGap          time.Duration `json:"max_gap"`
 }
 
 type TranscribeParams struct {
@@ -198,6 +228,9 @@ type TranscribeParams struct {
 	Punctuate              bool             `json:"punctuate"`
 	RedactPii              bool             `json:"redact_pii"`
 	RedactPiiPolicies      []string         `json:"redact_pii_policies"`
+	RedactPiiAudio         bool             `json:"redact_pii_audio,omitempty"`
+	RedactPiiAudioQuality  string           `json:"redact_pii_audio_quality,omitempty"`
+	RedactPiiSub           string           `json:"redact_pii_sub,omitempty"`
 	SentimentAnalysis      bool             `json:"sentiment_analysis"`
 	SpeakerLabels          bool             `json:"speaker_labels"`
 	Summarization          bool             `json:"summarization,omitempty"`
@@ -419,3 +452,323 @@ type SummaryObject struct {
 	Start    int    `json:"start"`
 	End      int    `json:"end"`
 }
+
+type TranscriptListParams struct {
+	Limit         int
+	Status        string
+	CreatedOn     string
+	BeforeID      string
+	AfterID       string
+	ThrottledOnly bool
+}
+
+type TranscriptList struct {
+	PageDetails PageDetails          `json:"page_details"`
+	Transcripts []TranscriptListItem `json:"transcripts"`
+}
+
+type PageDetails struct {
+	Limit       int64   `json:"limit"`
+	ResultCount int64   `json:"result_count"`
+	CurrentURL  string  `json:"current_url"`
+	PrevURL     *string `json:"prev_url"`
+	NextURL     *string `json:"next_url"`
+}
+
+type TranscriptListItem struct {
+	ID            string  `json:"id"`
+	ResourceURL   string  `json:"resource_url,omitempty"`
+	Status        string  `json:"status"`
+	Created       string  `json:"created"`
+	Completed     *string `json:"completed,omitempty"`
+	AudioURL      string  `json:"audio_url"`
+	AudioDuration *int64  `json:"audio_duration,omitempty"`
+	Error         *string `json:"error,omitempty"`
+}
+
+type ListFlags struct {
+	Json    bool      `json:"json"`
+	IdsOnly bool      `json:"ids_only"`
+	Limit   int       `json:"limit"`
+	After   time.Time `json:"after"`
+	Before  time.Time `json:"before"`
+}
+
+var TranscriptStatuses = []string{"queued", "processing", "completed", "error"}
+
+type DeleteFlags struct {
+	DryRun bool `json:"dry_run"`
+	Yes    bool `json:"yes"`
+}
+
+type BatchFlags struct {
+	Poll         bool           `json:"poll"`
+	Concurrency  int            `json:"concurrency"`
+	OutputDir    string         `json:"output_dir"`
+	Formats      []string       `json:"formats"`
+	Captions     CaptionOptions `json:"captions"`
+	Segmentation string         `json:"segmentation,omitempty"`
+}
+
+// BatchJob is one file or URL of a batch, with the parameters it's submitted
+// with. Name is the base name of its output files.
+type BatchJob struct {
+	Source string           `json:"source"`
+	Name   string           `json:"name"`
+	Params TranscribeParams `json:"params"`
+}
+
+type BatchResult struct {
+	Job     BatchJob `json:"job"`
+	ID      string   `json:"id"`
+	Status  string   `json:"status"`
+	Outputs []string `json:"outputs"`
+	Err     error    `json:"-"`
+}
+
+// BatchJournal is the saved state of a batch, used to resume it.
+type BatchJournal struct {
+	ID      string        `json:"id"`
+	Created time.Time     `json:"created"`
+	Flags   BatchFlags    `json:"flags"`
+	Items   []JournalItem `json:"items"`
+}
+
+// JournalItem tracks a job through the batch. Status is "pending" until the
+// file is uploaded, "uploaded" until it's submitted, and then the status of
+// the transcript. Error holds the last failure, if any.
+type JournalItem struct {
+	Job       BatchJob `json:"job"`
+	UploadURL string   `json:"upload_url,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	Status    string   `json:"status"`
+	Outputs   []string `json:"outputs,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// HistoryEntry is what the local history knows about a transcript. Source is
+// the file or URL it was submitted from, Params are only known for transcripts
+// submitted from this machine. Transcript is the last response fetched from the
+// API, removed when the transcript is deleted.
+type HistoryEntry struct {
+	ID         string            `json:"id"`
+	Source     string            `json:"source,omitempty"`
+	Params     *TranscribeParams `json:"params,omitempty"`
+	Status     string            `json:"status"`
+	Submitted  *time.Time        `json:"submitted,omitempty"`
+	Fetched    *time.Time        `json:"fetched,omitempty"`
+	Transcript json.RawMessage   `json:"transcript,omitempty"`
+}
+
+type HistoryFlags struct {
+	Json   bool   `json:"json"`
+	Limit  int    `json:"limit"`
+	Status string `json:"status"`
+}
+
+type SearchFlags struct {
+	Json    bool `json:"json"`
+	IdsOnly bool `json:"ids_only"`
+	Context int  `json:"context"`
+	Limit   int  `json:"limit"`
+}
+
+// SearchHit is a match of a search query in a transcript. Text is the matched
+// words, Before and After the context around them.
+type SearchHit struct {
+	ID      string `json:"id"`
+	Source  string `json:"source,omitempty"`
+	Start   int64  `json:"start"`
+	End     int64  `json:"end"`
+	Speaker string `json:"speaker,omitempty"`
+	Text    string `json:"text"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+}
+
+type WordsFlags struct {
+	Json  bool     `json:"json"`
+	Terms []string `json:"terms"`
+}
+
+// WordSearchResponse is the result of the word search endpoint. Timestamps
+// are the start and end of every match in milliseconds, Indexes the position
+// of their first word in the transcript.
+type WordSearchResponse struct {
+	ID         string            `json:"id"`
+	TotalCount int64             `json:"total_count"`
+	Matches    []WordSearchMatch `json:"matches"`
+}
+
+type WordSearchMatch struct {
+	Text       string     `json:"text"`
+	Count      int64      `json:"count"`
+	Timestamps [][2]int64 `json:"timestamps"`
+	Indexes    []int64    `json:"indexes"`
+}
+
+// SentencesResponse is the transcript split into sentences by the API.
+type SentencesResponse struct {
+	ID            string                    `json:"id"`
+	Confidence    float64                   `json:"confidence"`
+	AudioDuration float64                   `json:"audio_duration"`
+	Sentences     []SentimentAnalysisResult `json:"sentences"`
+}
+
+// ParagraphsResponse is the transcript split into paragraphs by the API.
+type ParagraphsResponse struct {
+	ID            string                    `json:"id"`
+	Confidence    float64                   `json:"confidence"`
+	AudioDuration float64                   `json:"audio_duration"`
+	Paragraphs    []SentimentAnalysisResult `json:"paragraphs"`
+}
+
+// RedactedAudioResponse is the state of the PII-redacted copy of a
+// transcript's audio. RedactedAudioURL is set once Status is
+// "redacted_audio_ready".
+type RedactedAudioResponse struct {
+	Status           string `json:"status"`
+	RedactedAudioURL string `json:"redacted_audio_url"`
+}
+
+type RedactedAudioFlags struct {
+	Output  string `json:"output"`
+	UrlOnly bool   `json:"url_only"`
+}
+
+// RealtimeParams configure a real-time session. SampleRate is the rate of the
+// 16-bit mono PCM audio that will be sent.
+type RealtimeParams struct {
+	SampleRate int      `json:"sample_rate"`
+	WordBoost  []string `json:"word_boost,omitempty"`
+}
+
+// RealtimeMessage is a message received during a real-time session.
+// MessageType is "SessionBegins", "PartialTranscript", "FinalTranscript" or
+// "SessionTerminated". Audio times are in milliseconds since the session
+// began.
+type RealtimeMessage struct {
+	MessageType   string                    `json:"message_type"`
+	SessionID     string                    `json:"session_id,omitempty"`
+	ExpiresAt     string                    `json:"expires_at,omitempty"`
+	AudioStart    int64                     `json:"audio_start,omitempty"`
+	AudioEnd      int64                     `json:"audio_end,omitempty"`
+	Confidence    float64                   `json:"confidence,omitempty"`
+	Text          string                    `json:"text,omitempty"`
+	Words         []SentimentAnalysisResult `json:"words,omitempty"`
+	Created       string                    `json:"created,omitempty"`
+	Punctuated    bool                      `json:"punctuated,omitempty"`
+	TextFormatted bool                      `json:"text_formatted,omitempty"`
+	Error         string                    `json:"error,omitempty"`
+	// Raw holds the message exactly as sent by the API.
+	Raw json.RawMessage `json:"-"`
+}
+
+// StreamFlags control the stream command. A zero SampleRate means the rate
+// of the WAV header, or 16000 for raw PCM. Realtime paces the audio at the
+// speed it plays.
+type StreamFlags struct {
+	Json       bool     `json:"json"`
+	Realtime   bool     `json:"realtime"`
+	SampleRate int      `json:"sample_rate"`
+	WordBoost  []string `json:"word_boost"`
+}
+
+// RealtimeToken is a temporary token that can start real-time sessions
+// without the API key. ExpiresAt is computed locally from the requested
+// expiry.
+type RealtimeToken struct {
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type StreamTokenFlags struct {
+	Json      bool `json:"json"`
+	ExpiresIn int  `json:"expires_in"`
+}
+
+// LemurParams are sent to the LeMUR endpoints. Prompt is only used by tasks,
+// Questions by question answering, and AnswerFormat by summaries and action
+// items.
+type LemurParams struct {
+	TranscriptIDs []string        `json:"transcript_ids"`
+	Context       string          `json:"context,omitempty"`
+	FinalModel    string          `json:"final_model,omitempty"`
+	MaxOutputSize int             `json:"max_output_size,omitempty"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	Prompt        string          `json:"prompt,omitempty"`
+	AnswerFormat  string          `json:"answer_format,omitempty"`
+	Questions     []LemurQuestion `json:"questions,omitempty"`
+}
+
+type LemurQuestion struct {
+	Question      string   `json:"question"`
+	Context       string   `json:"context,omitempty"`
+	AnswerFormat  string   `json:"answer_format,omitempty"`
+	AnswerOptions []string `json:"answer_options,omitempty"`
+}
+
+// LemurResponse is the result of a LeMUR request. Response is the generated
+// text, or a list of LemurAnswer for question answering.
+type LemurResponse struct {
+	RequestID string      `json:"request_id"`
+	Response  interface{} `json:"response"`
+	Usage     *LemurUsage `json:"usage,omitempty"`
+	// Raw holds the response body exactly as returned by the API.
+	Raw json.RawMessage `json:"-"`
+}
+
+type LemurAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+type LemurUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+type LemurFlags struct {
+	Json bool `json:"json"`
+}
+
+// ConfigSetting describes a key of the config file that can be changed with
+// assemblyai config set. Global settings are shared by every profile.
+type ConfigSetting struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Values      []string `json:"values,omitempty"`
+	Default     string   `json:"default"`
+	Env         string   `json:"env,omitempty"`
+	Global      bool     `json:"global"`
+	Description string   `json:"description"`
+}
+
+// ConfigValue is the value of a setting and where it comes from.
+type ConfigValue struct {
+	ConfigSetting
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type ConfigFlags struct {
+	Json bool `json:"json"`
+}
+
+// ConfigWhich is the effective configuration of the current directory.
+type ConfigWhich struct {
+	ProjectFile string        `json:"project_file"`
+	Profile     string        `json:"profile"`
+	Settings    []ConfigValue `json:"settings"`
+}
+
+// Preset is a named set of transcribe flags, keyed by flag name.
+type Preset struct {
+	Name   string            `json:"name"`
+	Source string            `json:"source"`
+	Flags  map[string]string `json:"flags"`
+}
+
+type PresetFlags struct {
+	Json bool `json:"json"`
+}
@@ -0,0 +1,530 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// IsBatchSource reports whether arg stands for several files, i.e. it's a
+// directory or a glob pattern.
+func IsBatchSource(arg string) bool {
+	if isUrl(arg) {
+		return false
+	}
+	if hasGlobMeta(arg) {
+		return true
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// isAudioFile reports whether the extension of path is one of
+// S.ValidFileTypes.
+func isAudioFile(path string) bool {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	return Contains(S.ValidFileTypes, extension)
+}
+
+// CollectSources expands args into the files and URLs to transcribe. URLs and
+// file paths are kept as they are, directories are walked recursively and
+// glob patterns expanded, keeping only files with a supported extension.
+func CollectSources(args []string) ([]string, error) {
+	sources := []string{}
+	seen := map[string]bool{}
+	add := func(source string) {
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	for _, arg := range args {
+		if isUrl(arg) {
+			add(arg)
+			continue
+		}
+		matches := []string{arg}
+		if hasGlobMeta(arg) {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s doesn't match any file", arg)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("%s doesn't exist", match)
+			}
+			if !info.IsDir() {
+				if match == arg || isAudioFile(match) {
+					add(match)
+				}
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !entry.IsDir() && isAudioFile(path) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sources, nil
+}
+
+// NewBatchJobs returns a job per source, all submitted with params.
+func NewBatchJobs(sources []string, params S.TranscribeParams) []S.BatchJob {
+	jobs := []S.BatchJob{}
+	for _, source := range sources {
+		jobs = append(jobs, S.BatchJob{Source: source, Params: params})
+	}
+	nameJobs(jobs)
+	return jobs
+}
+
+// ReadManifest reads the jobs of a CSV or JSON Lines manifest. Each row has a
+// "path" (or "audio_url") column with a file or URL, and may override any of
+// the transcription parameters in base by their API name, e.g.
+// "speaker_labels" or "language_code". CSV cells are read as JSON when they
+// parse as such, and as text otherwise. Relative paths are resolved against
+// the manifest's directory, and each row is checked with ValidateParams.
+func ReadManifest(manifest string, base S.TranscribeParams) ([]S.BatchJob, error) {
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	switch strings.ToLower(filepath.Ext(manifest)) {
+	case ".csv":
+		rows, err = readCSVManifest(data)
+	case ".jsonl", ".ndjson":
+		rows, err = readJSONLManifest(data)
+	default:
+		return nil, fmt.Errorf("%s isn't a .csv or .jsonl file", manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifest, err)
+	}
+
+	jobs := []S.BatchJob{}
+	for index, row := range rows {
+		source, _ := row["path"].(string)
+		if source == "" {
+			source, _ = row["audio_url"].(string)
+		}
+		if source == "" {
+			return nil, fmt.Errorf("%s: row %d has no path", manifest, index+1)
+		}
+		delete(row, "path")
+		delete(row, "audio_url")
+		if !isUrl(source) && !filepath.IsAbs(source) {
+			source = filepath.Join(filepath.Dir(manifest), source)
+		}
+		params, err := applyOverrides(base, row)
+		if err == nil {
+			err = ValidateParams(params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: row %d: %w", manifest, index+1, err)
+		}
+		jobs = append(jobs, S.BatchJob{Source: source, Params: params})
+	}
+	nameJobs(jobs)
+	return jobs, nil
+}
+
+func readCSVManifest(data []byte) ([]map[string]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the manifest is empty")
+	}
+	header := records[0]
+	rows := []map[string]interface{}{}
+	for _, record := range records[1:] {
+		row := map[string]interface{}{}
+		for column, cell := range record {
+			if cell == "" {
+				continue
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(cell), &value); err != nil {
+				value = cell
+			}
+			row[strings.TrimSpace(header[column])] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readJSONLManifest(data []byte) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// applyOverrides returns base with the fields named in overrides replaced.
+func applyOverrides(base S.TranscribeParams, overrides map[string]interface{}) (S.TranscribeParams, error) {
+	if len(overrides) == 0 {
+		return base, nil
+	}
+	data, err := json.Marshal(base)
+	if err != nil {
+		return base, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return base, err
+	}
+	for key, value := range overrides {
+		fields[key] = value
+	}
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return base, err
+	}
+	var params S.TranscribeParams
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return base, err
+	}
+	// Policies only apply with redact_pii, so a row turning it off drops
+	// those of the flags.
+	if _, ok := overrides["redact_pii_policies"]; !ok && !params.RedactPii {
+		params.RedactPiiPolicies = nil
+	}
+	return params, nil
+}
+
+// nameJobs gives each job the base name of its source, without extension,
+// adding -2, -3, ... when several sources share a name.
+func nameJobs(jobs []S.BatchJob) {
+	used := map[string]int{}
+	for i := range jobs {
+		name := jobs[i].Source
+		if isUrl(name) {
+			name = path.Base(strings.SplitN(name, "?", 2)[0])
+		} else {
+			name = filepath.Base(name)
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if name == "" || name == "." || name == "/" {
+			name = "transcript"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		jobs[i].Name = name
+	}
+}
+
+// RunBatch uploads, submits and polls the jobs of journal with at most
+// Concurrency of them in flight, and writes each transcript to the output
+// directory in every format. Every step is saved in the journal and jobs that
+// are already done are skipped. Progress is reported on stderr. Once ctx is
+// cancelled, jobs that haven't started are left for a later run.
+func RunBatch(ctx context.Context, client *C.Client, journal *Journal) []S.BatchResult {
+	flags := journal.Flags()
+	total := journal.Len()
+	results := make([]S.BatchResult, total)
+	indexes := make(chan int)
+	concurrency := flags.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	finished := 0
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				var err error
+				note := ""
+				item := journal.Item(index)
+				switch {
+				case ctx.Err() != nil:
+					err = ctx.Err()
+				case IsJournalItemDone(item):
+					note = " (done in an earlier run)"
+					if item.Error != "" {
+						err = errors.New(item.Error)
+					}
+				default:
+					err = runBatchJob(ctx, client, journal, index)
+				}
+				item = journal.Item(index)
+				results[index] = S.BatchResult{Job: item.Job, ID: item.ID, Status: item.Status, Outputs: item.Outputs, Err: err}
+				if ctx.Err() != nil && note == "" {
+					continue
+				}
+
+				mu.Lock()
+				finished++
+				status := item.Status
+				if err != nil {
+					status = "failed: " + apiErrorMessage(err)
+				}
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s: %s%s\n", finished, total, item.Job.Source, status, note)
+				mu.Unlock()
+			}
+		}()
+	}
+	for index := 0; index < total; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// runBatchJob takes a job from where the journal says it stopped: a file
+// that's already uploaded isn't uploaded again, and a transcript that's
+// already submitted is only polled.
+func runBatchJob(ctx context.Context, client *C.Client, journal *Journal, index int) error {
+	item := journal.Item(index)
+	flags := journal.Flags()
+	fail := func(err error) error {
+		journal.Update(index, func(item *S.JournalItem) {
+			item.Error = apiErrorMessage(err)
+		})
+		return err
+	}
+
+	if item.ID == "" {
+		audioURL := item.UploadURL
+		if isUrl(item.Job.Source) {
+			if isYoutubeLink(item.Job.Source) {
+				return fail(errors.New("YouTube links can't be transcribed in a batch"))
+			}
+			audioURL = item.Job.Source
+		}
+		if audioURL == "" {
+			file, err := os.Open(item.Job.Source)
+			if err != nil {
+				return fail(err)
+			}
+			var info os.FileInfo
+			if info, err = file.Stat(); err == nil {
+				audioURL, err = client.Upload(ctx, io.NewSectionReader(file, 0, info.Size()))
+			}
+			file.Close()
+			if err != nil {
+				return fail(err)
+			}
+			journal.Update(index, func(item *S.JournalItem) {
+				item.UploadURL = audioURL
+				item.Status = "uploaded"
+			})
+		}
+
+		params := item.Job.Params
+		params.AudioURL = audioURL
+		transcript, err := client.SubmitTranscript(ctx, params)
+		if err != nil {
+			return fail(err)
+		}
+		RecordSubmitted(item.Job.Source, params, *transcript)
+		journal.Update(index, func(item *S.JournalItem) {
+			item.ID = *transcript.ID
+			item.Status = *transcript.Status
+			item.Error = ""
+		})
+		item = journal.Item(index)
+	}
+	if !flags.Poll {
+		return nil
+	}
+
+	transcript, err := client.WaitForTranscript(ctx, item.ID, pollInterval)
+	if err != nil {
+		return fail(err)
+	}
+	RecordFetched(*transcript)
+	journal.Update(index, func(item *S.JournalItem) {
+		item.Status = *transcript.Status
+	})
+	if transcript.Error != nil {
+		return fail(errors.New(*transcript.Error))
+	}
+	if flags.Segmentation != "" {
+		if err := fetchSegments(ctx, client, transcript, flags.Segmentation); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := os.MkdirAll(flags.OutputDir, 0755); err != nil {
+		return fail(err)
+	}
+	outputs := []string{}
+	for _, format := range flags.Formats {
+		formatter, _ := LookupFormatter(format)
+		output := filepath.Join(flags.OutputDir, item.Job.Name+"."+formatter.Extension)
+		if err := renderFile(output, formatter, *transcript, S.TranscribeFlags{Captions: flags.Captions}); err != nil {
+			return fail(err)
+		}
+		outputs = append(outputs, output)
+	}
+	journal.Update(index, func(item *S.JournalItem) {
+		item.Outputs = outputs
+		item.Error = ""
+	})
+	return nil
+}
+
+// TranscribeBatch starts a batch of jobs, journaled so it can be resumed with
+// ResumeBatch, and prints a summary table of the results.
+func TranscribeBatch(client *C.Client, jobs []S.BatchJob, flags S.BatchFlags) {
+	if len(jobs) == 0 {
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New("No files to transcribe"),
+			Message: "No files to transcribe. Directories and patterns only include files with a supported extension.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	TelemetryCaptureEvent("CLI batch transcription created", nil)
+
+	journal, err := NewJournal(jobs, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not save the batch journal, this batch can't be resumed: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Started batch job %s.\n", journal.ID())
+	}
+	runJournal(client, journal)
+}
+
+// ResumeBatch continues the batch id. Finished jobs are skipped, transcripts
+// that were submitted are polled, and the other jobs start over. Transcripts
+// are always polled, even if the batch was started without polling. The value
+// of the webhook header isn't journaled, so it's given again.
+func ResumeBatch(client *C.Client, id string, webhookAuthHeaderValue string) {
+	journal, err := LoadJournal(id)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Could not resume the batch job: %v.", err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	if header := journal.WebhookAuthHeaderName(); header != "" && webhookAuthHeaderValue == "" {
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New("Missing webhook header value"),
+			Message: fmt.Sprintf("The batch sends webhooks with the %s header, whose value isn't saved. Pass it again with --webhook_auth_header_value.", header),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	journal.SetWebhookAuthHeaderValue(webhookAuthHeaderValue)
+	journal.SetPoll(true)
+	runJournal(client, journal)
+}
+
+// runJournal runs a batch until it's done or interrupted, then prints its
+// summary and exits with the error of the first failed job, if any.
+func runJournal(client *C.Client, journal *Journal) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results := RunBatch(ctx, client, journal)
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "\nInterrupted. Run assemblyai batch resume %s to continue.\n", journal.ID())
+		os.Exit(ExitInterrupted)
+	}
+	batchSummaryPrintFormatted(os.Stdout, results, journal.Flags().Poll)
+
+	for _, result := range results {
+		if result.Err != nil {
+			message := "Some files could not be transcribed."
+			if journal.path != "" {
+				message += fmt.Sprintf(" Run assemblyai batch resume %s to retry them.", journal.ID())
+			}
+			printErrorProps := S.PrintErrorProps{
+				Error:   result.Err,
+				Message: message,
+			}
+			PrintError(printErrorProps)
+			return
+		}
+	}
+}
+
+func batchSummaryPrintFormatted(w io.Writer, results []S.BatchResult, poll bool) {
+	table := uitable.New()
+	table.Wrap = true
+	table.Separator = " |\t"
+	table.AddRow("| source", "id", "status", "result")
+	succeeded := 0
+	for _, result := range results {
+		detail := strings.Join(result.Outputs, ", ")
+		if result.Err != nil {
+			detail = apiErrorMessage(result.Err)
+		} else {
+			succeeded++
+		}
+		table.AddRow("| "+result.Job.Source, result.ID, result.Status, detail)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+	verb := "Transcribed"
+	if !poll {
+		verb = "Submitted"
+	}
+	fmt.Fprintf(w, "%s %d of %d file(s).\n", verb, succeeded, len(results))
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollectSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "calls/a.mp3", "calls/notes.txt", "calls/2022/b.WAV", "other/c.m4a", "other/d.mp3", "readme.md")
+	join := func(names ...string) []string {
+		paths := []string{}
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"directory is walked recursively", join("calls"), join("calls/2022/b.WAV", "calls/a.mp3"), false},
+		{"glob keeps audio files", join("other/*"), join("other/c.m4a", "other/d.mp3"), false},
+		{"explicit files are kept", join("readme.md"), join("readme.md"), false},
+		{"duplicates are removed", join("other/d.mp3", "other/*.mp3"), join("other/d.mp3"), false},
+		{"urls", []string{"https://example.com/a.mp3"}, []string{"https://example.com/a.mp3"}, false},
+		{"missing file", join("missing.mp3"), nil, true},
+		{"glob without matches", join("*.flac"), nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CollectSources(test.args)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error=%v, got %v", test.wantErr, err)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+	if !IsBatchSource(filepath.Join(dir, "calls")) || !IsBatchSource("*.mp3") || IsBatchSource(filepath.Join(dir, "readme.md")) {
+		t.Error("Expected directories and patterns to be batch sources, and files not to be.")
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	base := S.TranscribeParams{Punctuate: true, FormatText: true}
+
+	csvManifest := filepath.Join(dir, "jobs.csv")
+	os.WriteFile(csvManifest, []byte("path,speaker_labels,language_code,word_boost\n"+
+		"calls/a.mp3,true,es,\"[\"\"refund\"\"]\"\n"+
+		"https://example.com/a.mp3,,,\n"), 0644)
+	jobs, err := ReadManifest(csvManifest, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got %+v", jobs)
+	}
+	first := jobs[0]
+	if first.Source != filepath.Join(dir, "calls/a.mp3") || first.Name != "a" {
+		t.Errorf("Expected the path to be resolved against the manifest, got %s (%s).", first.Source, first.Name)
+	}
+	if !first.Params.SpeakerLabels || *first.Params.LanguageCode != "es" || !reflect.DeepEqual(first.Params.WordBoost, []string{"refund"}) || !first.Params.Punctuate {
+		t.Errorf("Expected the row to override the base parameters, got %+v", first.Params)
+	}
+	if jobs[1].Source != "https://example.com/a.mp3" || jobs[1].Name != "a-2" || jobs[1].Params.SpeakerLabels {
+		t.Errorf("Expected the URL to keep the base parameters under a unique name, got %+v", jobs[1])
+	}
+
+	jsonlManifest := filepath.Join(dir, "jobs.jsonl")
+	os.WriteFile(jsonlManifest, []byte("{\"path\": \"/abs/b.mp3\", \"auto_chapters\": true}\n\n{\"audio_url\": \"c.wav\"}\n"), 0644)
+	jobs, err = ReadManifest(jsonlManifest, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].Source != "/abs/b.mp3" || !jobs[0].Params.AutoChapters || jobs[1].Source != filepath.Join(dir, "c.wav") {
+		t.Errorf("Expected the JSON Lines jobs, got %+v", jobs)
+	}
+
+	redacted := S.TranscribeParams{RedactPii: true, RedactPiiPolicies: []string{"person_name"}}
+	os.WriteFile(jsonlManifest, []byte("{\"path\": \"a.mp3\", \"redact_pii\": false}\n"), 0644)
+	jobs, err = ReadManifest(jsonlManifest, redacted)
+	if err != nil || jobs[0].Params.RedactPii || jobs[0].Params.RedactPiiPolicies != nil {
+		t.Errorf("Expected a row turning off redact_pii to drop the policies of the flags, got %+v, %v", jobs, err)
+	}
+
+	invalid := map[string]string{
+		"unknown.csv":     "path,speaker_lables\na.mp3,true\n",
+		"nopath.jsonl":    "{\"speaker_labels\": true}\n",
+		"wrongtype.jsonl": "{\"path\": \"a.mp3\", \"speaker_labels\": \"yes\"}\n",
+		"jobs.txt":        "a.mp3\n",
+		"dual.jsonl":      "{\"path\": \"a.mp3\", \"dual_channel\": true, \"speaker_labels\": true}\n",
+		"summary.csv":     "path,summarization,auto_chapters,summary_type\na.mp3,true,true,bullets\n",
+		"policies.jsonl":  "{\"path\": \"a.mp3\", \"redact_pii_policies\": [\"person_name\"]}\n",
+		"boost.jsonl":     "{\"path\": \"a.mp3\", \"word_boost\": [\"refund\"], \"boost_param\": \"max\"}\n",
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := ReadManifest(path, base); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected %s to be rejected with its name in the error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+// Cue is a single caption, shared by the SRT and WebVTT renderers so both
+// formats always break at the same places.
+type Cue struct {
+	Start   int64
+	End     int64
+	Speaker string
+	Lines   []string
+}
+
+// DefaultCaptionOptions follows common subtitling guidelines: two lines of at
+// most 42 characters, shown for one to seven seconds.
+var DefaultCaptionOptions = S.CaptionOptions{
+	CharsPerLine:    42,
+	LinesPerCaption: 2,
+	MinDuration:     time.Second,
+	MaxDuration:     7 * time.Second,
+	MaxGap:          time.Second,
+}
+
+// ValidateCaptionOptions reports options that can't produce captions.
+func ValidateCaptionOptions(opts S.CaptionOptions) error {
+	if opts.CharsPerLine < 0 || opts.LinesPerCaption < 0 {
+		return errors.New("characters per line and lines per caption can't be negative")
+	}
+	if opts.MinDuration < 0 || opts.MaxDuration < 0 || opts.MaxGap < 0 {
+		return errors.New("caption durations can't be negative")
+	}
+	if opts.MaxDuration > 0 && opts.MinDuration > opts.MaxDuration {
+		return fmt.Errorf("the minimum caption duration %s is longer than the maximum %s", opts.MinDuration, opts.MaxDuration)
+	}
+	return nil
+}
+
+// BuildCues groups words into cues. A new cue starts when the speaker changes,
+// when there's a pause longer than opts.MaxGap, or when the next word would
+// overflow the cue's lines or opts.MaxDuration. A cue also ends with a
+// sentence, once it has been on screen for at least opts.MinDuration. Cues
+// shorter than that are then stretched up to the start of the next one.
+func BuildCues(words []S.SentimentAnalysisResult, opts S.CaptionOptions) []Cue {
+	cues := []Cue{}
+	var current *Cue
+	var text []string
+	flush := func() {
+		if current != nil {
+			current.Lines = wrapLines(text, opts.CharsPerLine)
+			cues = append(cues, *current)
+			current = nil
+			text = nil
+		}
+	}
+	for _, word := range words {
+		if word.Start == nil || word.End == nil {
+			continue
+		}
+		if current != nil && breaksBefore(*current, text, word, opts) {
+			flush()
+		}
+		if current == nil {
+			current = &Cue{Start: *word.Start, Speaker: word.Speaker}
+		}
+		current.End = *word.End
+		text = append(text, word.Text)
+		if endsSentence(word.Text) && milliseconds(current.End-current.Start) >= opts.MinDuration {
+			flush()
+		}
+	}
+	flush()
+	extendShortCues(cues, opts.MinDuration)
+	return cues
+}
+
+// breaksBefore reports whether word has to start a new cue instead of being
+// added to current, which holds text so far.
+func breaksBefore(current Cue, text []string, word S.SentimentAnalysisResult, opts S.CaptionOptions) bool {
+	if word.Speaker != current.Speaker {
+		return true
+	}
+	if opts.MaxGap > 0 && milliseconds(*word.Start-current.End) > opts.MaxGap {
+		return true
+	}
+	if opts.MaxDuration > 0 && milliseconds(*word.End-current.Start) > opts.MaxDuration {
+		return true
+	}
+	if opts.CharsPerLine > 0 && opts.LinesPerCaption > 0 {
+		next := append(append([]string{}, text...), word.Text)
+		if len(wrapLines(next, opts.CharsPerLine)) > opts.LinesPerCaption {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapLines fills lines of at most limit characters, word by word. A word
+// longer than limit gets a line of its own. A limit of 0 keeps a single line.
+func wrapLines(words []string, limit int) []string {
+	if limit <= 0 {
+		return []string{strings.Join(words, " ")}
+	}
+	lines := []string{}
+	line := ""
+	for _, word := range words {
+		if line == "" {
+			line = word
+		} else if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= limit {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// extendShortCues keeps every cue on screen for at least min, without
+// overlapping the cue that follows it.
+func extendShortCues(cues []Cue, min time.Duration) {
+	for i := range cues {
+		if milliseconds(cues[i].End-cues[i].Start) >= min {
+			continue
+		}
+		end := cues[i].Start + min.Milliseconds()
+		if i+1 < len(cues) && end > cues[i+1].Start {
+			end = cues[i+1].Start
+		}
+		if end > cues[i].End {
+			cues[i].End = end
+		}
+	}
+}
+
+func endsSentence(text string) bool {
+	return strings.HasSuffix(text, ".") || strings.HasSuffix(text, "?") || strings.HasSuffix(text, "!")
+}
+
+func milliseconds(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// CaptionWords returns the words to build captions from. When the transcript
+// has speaker labels or dual channel utterances, each word's Speaker is set to
+// the voice it belongs to, e.g. "Speaker A" or "Channel 1".
+func CaptionWords(transcript S.TranscriptResponse) []S.SentimentAnalysisResult {
+	isDualChannel := transcript.DualChannel != nil && *transcript.DualChannel
+	if transcript.Utterances == nil || (!transcript.SpeakerLabels && !isDualChannel) {
+		words := make([]S.SentimentAnalysisResult, len(transcript.Words))
+		for i, word := range transcript.Words {
+			word.Speaker = ""
+			words[i] = word
+		}
+		return words
+	}
+	words := []S.SentimentAnalysisResult{}
+	for _, utterance := range *transcript.Utterances {
+		voice := "Speaker " + utterance.Speaker
+		if isDualChannel {
+			voice = "Channel " + utterance.Channel
+		}
+		for _, word := range utterance.Words {
+			word.Speaker = voice
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func GetSrtText(words []S.SentimentAnalysisResult, opts S.CaptionOptions) string {
+	var srtText strings.Builder
+	for index, cue := range BuildCues(words, opts) {
+		fmt.Fprintf(
+			&srtText,
+			"%d\n%s --> %s\n%s\n\n",
+			index+1,
+			TransformMsToTimestamp(cue.Start, true),
+			TransformMsToTimestamp(cue.End, true),
+			strings.Join(cue.Lines, "\n"),
+		)
+	}
+	return srtText.String()
+}
+
+func GetVttText(words []S.SentimentAnalysisResult, opts S.CaptionOptions) string {
+	var vttText strings.Builder
+	vttText.WriteString("WEBVTT\n\n")
+	for _, cue := range BuildCues(words, opts) {
+		lines := vttEscaper.Replace(strings.Join(cue.Lines, "\n"))
+		if cue.Speaker != "" {
+			lines = fmt.Sprintf("<v %s>%s", vttEscaper.Replace(cue.Speaker), lines)
+		}
+		fmt.Fprintf(&vttText, "%s --> %s\n%s\n\n", vttTimestamp(cue.Start), vttTimestamp(cue.End), lines)
+	}
+	return vttText.String()
+}
+
+// vttEscaper escapes the characters WebVTT cue text can't hold. Escaping >
+// also breaks up the --> that separates cue timings, and keeps speaker names
+// from closing their voice tag.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// vttTimestamp formats ms as hh:mm:ss.ttt.
+func vttTimestamp(ms int64) string {
+	return strings.Replace(TransformMsToTimestamp(ms, true), ",", ".", 1)
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestCaptions(t *testing.T) {
+	transcript := loadTranscript(t)
+	dualChannel := false
+	transcript.DualChannel = &dualChannel
+
+	speakerWords := CaptionWords(transcript)
+	assertGolden(t, "srt_speakers", []byte(GetSrtText(speakerWords, DefaultCaptionOptions)))
+	assertGolden(t, "vtt_speakers", []byte(GetVttText(speakerWords, DefaultCaptionOptions)))
+
+	transcript.SpeakerLabels = false
+	plainWords := CaptionWords(transcript)
+	assertGolden(t, "vtt", []byte(GetVttText(plainWords, DefaultCaptionOptions)))
+
+	dualChannel = true
+	channelWords := CaptionWords(transcript)
+	if channelWords[0].Speaker != "Channel 1" || channelWords[len(channelWords)-1].Speaker != "Channel 2" {
+		t.Errorf("Expected dual channel words to be labelled by channel, got %s and %s.", channelWords[0].Speaker, channelWords[len(channelWords)-1].Speaker)
+	}
+}
+
+func TestBuildCuesSharedBySrtAndVtt(t *testing.T) {
+	words := CaptionWords(loadTranscript(t))
+	cues := BuildCues(words, DefaultCaptionOptions)
+	srt := GetSrtText(words, DefaultCaptionOptions)
+	vtt := GetVttText(words, DefaultCaptionOptions)
+	for _, cue := range cues {
+		if !strings.Contains(srt, TransformMsToTimestamp(cue.Start, true)+" --> "+TransformMsToTimestamp(cue.End, true)) {
+			t.Errorf("Expected SRT cue %d-%d.", cue.Start, cue.End)
+		}
+		if !strings.Contains(vtt, vttTimestamp(cue.Start)+" --> "+vttTimestamp(cue.End)) {
+			t.Errorf("Expected VTT cue %d-%d.", cue.Start, cue.End)
+		}
+	}
+}
+
+func TestVttEscaping(t *testing.T) {
+	words := timedWords("if a<b && c --> d")
+	for i := range words {
+		words[i].Speaker = "<A>"
+	}
+	want := "WEBVTT\n\n00:00:00.000 --> 00:00:02.900\n<v &lt;A&gt;>if a&lt;b &amp;&amp; c --&gt; d\n\n"
+	if got := GetVttText(words, S.CaptionOptions{}); got != want {
+		t.Errorf("Expected the cue text to be escaped, got %q.", got)
+	}
+}
+
+// timedWords spaces words 500ms apart, each lasting 400ms, starting at 0.
+func timedWords(text string) []S.SentimentAnalysisResult {
+	words := []S.SentimentAnalysisResult{}
+	for i, field := range strings.Fields(text) {
+		start := int64(i * 500)
+		end := start + 400
+		words = append(words, S.SentimentAnalysisResult{Text: field, Start: &start, End: &end})
+	}
+	return words
+}
+
+func TestBuildCues(t *testing.T) {
+	paused := timedWords("before the pause after")
+	for i := 2; i < len(paused); i++ {
+		start := *paused[i].Start + 3000
+		end := *paused[i].End + 3000
+		paused[i].Start, paused[i].End = &start, &end
+	}
+	speakers := timedWords("Hi there hello you")
+	speakers[2].Speaker, speakers[3].Speaker = "B", "B"
+	untimed := timedWords("one two three")
+	untimed[1].Start = nil
+
+	tests := []struct {
+		name  string
+		words []S.SentimentAnalysisResult
+		opts  S.CaptionOptions
+		want  []Cue
+	}{
+		{
+			name:  "no constraints breaks on sentences",
+			words: timedWords("One two. Three four? Five six!"),
+			opts:  S.CaptionOptions{},
+			want: []Cue{
+				{Start: 0, End: 900, Lines: []string{"One two."}},
+				{Start: 1000, End: 1900, Lines: []string{"Three four?"}},
+				{Start: 2000, End: 2900, Lines: []string{"Five six!"}},
+			},
+		},
+		{
+			name:  "unpunctuated text wraps into lines and cues",
+			words: timedWords("aaa bbb ccc ddd eee fff ggg"),
+			opts:  S.CaptionOptions{CharsPerLine: 7, LinesPerCaption: 2},
+			want: []Cue{
+				{Start: 0, End: 1900, Lines: []string{"aaa bbb", "ccc ddd"}},
+				{Start: 2000, End: 3400, Lines: []string{"eee fff", "ggg"}},
+			},
+		},
+		{
+			name:  "non-ASCII text wraps by characters",
+			words: timedWords("été déjà über ñandú"),
+			opts:  S.CaptionOptions{CharsPerLine: 10},
+			want: []Cue{
+				{Start: 0, End: 1900, Lines: []string{"été déjà", "über ñandú"}},
+			},
+		},
+		{
+			name:  "long word gets its own line",
+			words: timedWords("a supercalifragilistic b"),
+			opts:  S.CaptionOptions{CharsPerLine: 5},
+			want: []Cue{
+				{Start: 0, End: 1400, Lines: []string{"a", "supercalifragilistic", "b"}},
+			},
+		},
+		{
+			name:  "max duration",
+			words: timedWords("one two three four five"),
+			opts:  S.CaptionOptions{MaxDuration: 1500 * time.Millisecond},
+			want: []Cue{
+				{Start: 0, End: 1400, Lines: []string{"one two three"}},
+				{Start: 1500, End: 2400, Lines: []string{"four five"}},
+			},
+		},
+		{
+			name:  "short sentences are merged up to min duration",
+			words: timedWords("Yes. No. Maybe. Sure."),
+			opts:  S.CaptionOptions{MinDuration: time.Second},
+			want: []Cue{
+				{Start: 0, End: 1400, Lines: []string{"Yes. No. Maybe."}},
+				{Start: 1500, End: 2500, Lines: []string{"Sure."}},
+			},
+		},
+		{
+			name:  "short final cue is stretched to min duration",
+			words: timedWords("One two three. Four."),
+			opts:  S.CaptionOptions{MinDuration: time.Second},
+			want: []Cue{
+				{Start: 0, End: 1400, Lines: []string{"One two three."}},
+				{Start: 1500, End: 2500, Lines: []string{"Four."}},
+			},
+		},
+		{
+			name:  "short cue is not stretched over the next one",
+			words: speakers,
+			opts:  S.CaptionOptions{MinDuration: 1500 * time.Millisecond},
+			want: []Cue{
+				{Start: 0, End: 1000, Lines: []string{"Hi there"}},
+				{Start: 1000, End: 2500, Speaker: "B", Lines: []string{"hello you"}},
+			},
+		},
+		{
+			name:  "pause",
+			words: paused,
+			opts:  S.CaptionOptions{MaxGap: time.Second},
+			want: []Cue{
+				{Start: 0, End: 900, Lines: []string{"before the"}},
+				{Start: 4000, End: 4900, Lines: []string{"pause after"}},
+			},
+		},
+		{
+			name:  "speaker change",
+			words: speakers,
+			opts:  DefaultCaptionOptions,
+			want: []Cue{
+				{Start: 0, End: 1000, Lines: []string{"Hi there"}},
+				{Start: 1000, End: 2000, Speaker: "B", Lines: []string{"hello you"}},
+			},
+		},
+		{
+			name:  "words without timestamps are skipped",
+			words: untimed,
+			opts:  S.CaptionOptions{},
+			want: []Cue{
+				{Start: 0, End: 1400, Lines: []string{"one three"}},
+			},
+		},
+		{
+			name:  "no words",
+			words: nil,
+			opts:  DefaultCaptionOptions,
+			want:  []Cue{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := BuildCues(test.words, test.opts)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateCaptionOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  S.CaptionOptions
+		valid bool
+	}{
+		{"defaults", DefaultCaptionOptions, true},
+		{"no constraints", S.CaptionOptions{}, true},
+		{"negative chars", S.CaptionOptions{CharsPerLine: -1}, false},
+		{"negative gap", S.CaptionOptions{MaxGap: -time.Second}, false},
+		{"min longer than max", S.CaptionOptions{MinDuration: 2 * time.Second, MaxDuration: time.Second}, false},
+		{"min without max", S.CaptionOptions{MinDuration: 2 * time.Second}, true},
+	}
+	for _, test := range tests {
+		if err := ValidateCaptionOptions(test.opts); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%v, got %v", test.name, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+// Default timeouts for establishing a connection and waiting for a response.
+const (
+	DefaultConnectTimeout = 30 * time.Second
+	DefaultReadTimeout    = 60 * time.Second
+)
+
+// FlagOverrides holds config values given as global flags, keyed by their
+// config file key.
+var FlagOverrides = map[string]string{}
+
+// LookupSetting resolves a config value from the global flags, then the env
+// variable, then the active profile.
+func LookupSetting(key string, env string) string {
+	if value, ok := FlagOverrides[key]; ok {
+		return value
+	}
+	if env != "" {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+	return GetProfileValue(key)
+}
+
+// NewHTTPClient returns an *http.Client honouring the configured proxy, CA
+// bundle, client certificate and timeouts. It is used for every request the
+// CLI makes, including those that don't go to the API.
+func NewHTTPClient() *http.Client {
+	opts := C.TransportOptions{
+		Proxy:          LookupSetting("api.proxy", "ASSEMBLYAI_PROXY"),
+		CABundle:       LookupSetting("api.ca_bundle", "ASSEMBLYAI_CA_BUNDLE"),
+		ClientCert:     LookupSetting("api.client_cert", "ASSEMBLYAI_CLIENT_CERT"),
+		ClientKey:      LookupSetting("api.client_key", "ASSEMBLYAI_CLIENT_KEY"),
+		ConnectTimeout: lookupDuration("connect timeout", "api.connect_timeout", "ASSEMBLYAI_CONNECT_TIMEOUT", DefaultConnectTimeout),
+		ReadTimeout:    lookupDuration("read timeout", "api.read_timeout", "ASSEMBLYAI_READ_TIMEOUT", DefaultReadTimeout),
+	}
+	httpClient, err := C.NewHTTPClient(opts)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Invalid connection settings: %s", err),
+		}
+		PrintError(printErrorProps)
+		return nil
+	}
+	return httpClient
+}
+
+// NewClient returns an API client for token, configured from the global flags,
+// the environment and the config file.
+func NewClient(token string) *C.Client {
+	retry := C.DefaultRetryPolicy
+	if value := LookupSetting("api.max_retries", "ASSEMBLYAI_MAX_RETRIES"); value != "" {
+		maxRetries, err := strconv.Atoi(value)
+		if err != nil || maxRetries < 0 {
+			printInvalidSetting("max retries", value, err)
+		}
+		retry.MaxRetries = maxRetries
+	}
+	retry.Timeout = lookupDuration("retry timeout", "api.retry_timeout", "ASSEMBLYAI_RETRY_TIMEOUT", retry.Timeout)
+
+	return C.New(
+		token,
+		C.WithBaseURL(baseURL()),
+		C.WithHTTPClient(NewHTTPClient()),
+		C.WithRetryPolicy(retry),
+	)
+}
+
+// baseURL returns the configured API host without a trailing slash, or the
+// default one.
+func baseURL() string {
+	baseURL := strings.TrimSuffix(LookupSetting("api.base_url", "ASSEMBLYAI_BASE_URL"), "/")
+	if baseURL == "" {
+		return C.DefaultBaseURL
+	}
+	return baseURL
+}
+
+func lookupDuration(name string, key string, env string, fallback time.Duration) time.Duration {
+	value := LookupSetting(key, env)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		printInvalidSetting(name, value, err)
+	}
+	return duration
+}
+
+func printInvalidSetting(name string, value string, err error) {
+	if err == nil {
+		err = errors.New("negative value")
+	}
+	printErrorProps := S.PrintErrorProps{
+		Error:   err,
+		Message: fmt.Sprintf("Invalid %s %q.", name, value),
+	}
+	PrintError(printErrorProps)
+}
+
+// Authenticate loads the token of the active profile and returns a client for
+// it. It exits with a hint on how to configure the CLI when the token is
+// missing or rejected by the API.
+func Authenticate() *C.Client {
+	if RequireToken() == "" {
+		return nil
+	}
+
+	client := NewClient(Token)
+	pollInterval = lookupDuration("poll interval", "api.poll_interval", "ASSEMBLYAI_POLL_INTERVAL", pollInterval)
+	checkToken := CheckIfTokenValid(client)
+	if !checkToken {
+		printErrorProps := S.PrintErrorProps{
+			Error:   ErrInvalidToken,
+			Message: INVALID_TOKEN,
+		}
+		PrintError(printErrorProps)
+		return nil
+	}
+	return client
+}
+
+// RequireToken sets Token to the stored token of the active profile without
+// checking it with the API, and reports an error when there is none.
+func RequireToken() string {
+	CheckActiveProfile()
+	Token = GetStoredToken()
+	if Token == "" {
+		printErrorProps := S.PrintErrorProps{
+			Error:   ErrNoToken,
+			Message: "Please start by running \033[1m\033[34massemblyai config [token]\033[0m",
+		}
+		if profile := ActiveProfile(); profile != DefaultProfile {
+			printErrorProps.Message = fmt.Sprintf("Please start by running \033[1m\033[34massemblyai config --profile %s [token]\033[0m", profile)
+		}
+		PrintError(printErrorProps)
+	}
+	return Token
+}
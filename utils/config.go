@@ -1,13 +1,15 @@
 package utils
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
+	C "github.com/AssemblyAI/assemblyai-cli/client"
 	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -20,13 +22,13 @@ var ConfigFileName = "config.toml"
 var Token string
 var DistinctId string
 
-func CheckIfTokenValid() bool {
-	response := QueryApi("/account", "GET", nil, nil)
-	if response == nil {
-		return false
-	}
-	var result S.Account
-	if err := json.Unmarshal(response, &result); err != nil {
+func CheckIfTokenValid(client *C.Client) bool {
+	result, err := client.GetAccount(context.Background())
+	if err != nil {
+		var authErr *C.AuthenticationError
+		if errors.As(err, &authErr) {
+			return false
+		}
 		printErrorProps := S.PrintErrorProps{
 			Error:   err,
 			Message: "Something went wrong. Please try again.",
@@ -72,29 +74,104 @@ func SetConfigFileValue(key string, value string) {
 	viper.SetConfigName("config") // name of config file (without extension)
 	viper.SetConfigType("toml")
 	viper.AddConfigPath(configFolder)
+	viper.ReadInConfig()
 	viper.Set(key, value)
 	viper.WriteConfig()
 }
 
 func GetConfigFileValue(key string) string {
+	if !readConfigFile() {
+		return ""
+	}
+	return viper.GetString(key)
+}
+
+// getConfigFileMap returns the table stored under key, or nil.
+func getConfigFileMap(key string) map[string]interface{} {
+	if !readConfigFile() {
+		return nil
+	}
+	return viper.GetStringMap(key)
+}
+
+func readConfigFile() bool {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return ""
+		return false
 	}
 	configFolder := filepath.Join(home, ConfigFolderPath)
 	configFile := filepath.Join(configFolder, ConfigFileName)
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return ""
+		return false
 	}
 	viper.SetConfigName("config") // name of config file (without extension)
 	viper.SetConfigType("toml")
 	viper.AddConfigPath(configFolder)
 	viper.ReadInConfig()
-	return viper.GetString(key)
+	return true
 }
 
+// UnsetConfigFileValue removes key, and the tables it leaves empty, from the
+// config file. It reports whether key was set.
+func UnsetConfigFileValue(key string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	configFile := filepath.Join(home, ConfigFolderPath, ConfigFileName)
+	stored := viper.New()
+	stored.SetConfigFile(configFile)
+	stored.SetConfigType("toml")
+	if err := stored.ReadInConfig(); err != nil {
+		return false
+	}
+	settings := stored.AllSettings()
+	if !deleteConfigKey(settings, strings.Split(strings.ToLower(key), ".")) {
+		return false
+	}
+	updated := viper.New()
+	updated.SetConfigType("toml")
+	updated.MergeConfigMap(settings)
+	if err := updated.WriteConfigAs(configFile); err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Something went wrong. Please try again.",
+		}
+		PrintError(printErrorProps)
+		return false
+	}
+	// Values set earlier in this run would otherwise be written back.
+	viper.Reset()
+	return true
+}
+
+func deleteConfigKey(settings map[string]interface{}, path []string) bool {
+	if len(path) == 1 {
+		_, ok := settings[path[0]]
+		delete(settings, path[0])
+		return ok
+	}
+	table, ok := settings[path[0]].(map[string]interface{})
+	if !ok || !deleteConfigKey(table, path[1:]) {
+		return false
+	}
+	if len(table) == 0 {
+		delete(settings, path[0])
+	}
+	return true
+}
+
+// GetStoredToken returns the token of the active profile, see LookupToken.
 func GetStoredToken() string {
-	return GetConfigFileValue("config.token")
+	token, source, err := LookupToken()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Could not read the token from %s: %s.", source, err),
+		}
+		PrintError(printErrorProps)
+	}
+	return token
 }
 
 func SetUserAlias() {
@@ -0,0 +1,288 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// Where tokens are stored. TokenStorageAuto uses the keyring when there is
+// one, then the encrypted file when its password is set in the environment,
+// and the config file otherwise.
+const (
+	TokenStorageAuto      = "auto"
+	TokenStorageKeyring   = "keyring"
+	TokenStorageEncrypted = "encrypted-file"
+	TokenStoragePlaintext = "plaintext"
+)
+
+// TokenStorages lists the values accepted by --token-storage.
+var TokenStorages = []string{TokenStorageAuto, TokenStorageKeyring, TokenStorageEncrypted, TokenStoragePlaintext}
+
+// CredentialsFileName is the encrypted file holding the tokens of every
+// profile, next to the config file.
+var CredentialsFileName = "credentials.enc"
+
+// keyringService is the service the tokens are stored under in the keyring,
+// with the profile as user.
+const keyringService = "assemblyai-cli"
+
+// Where LookupToken found the token.
+const (
+	TokenSourceFlag      = "the --token flag"
+	TokenSourceEnv       = "the ASSEMBLYAI_API_KEY environment variable"
+	TokenSourceKeyring   = "the keyring"
+	TokenSourceEncrypted = "the encrypted token file"
+	TokenSourceConfig    = "the config file"
+)
+
+// LookupToken returns the token of the active profile and where it was
+// found: the --token flag, then the ASSEMBLYAI_API_KEY env variable, then the
+// keyring or the encrypted file if the token was stored there, then the
+// config file. It returns "" when there's no token.
+func LookupToken() (string, string, error) {
+	if token := FlagOverrides["config.token"]; token != "" {
+		return token, TokenSourceFlag, nil
+	}
+	if token := os.Getenv("ASSEMBLYAI_API_KEY"); token != "" {
+		return token, TokenSourceEnv, nil
+	}
+	profile := ActiveProfile()
+	switch GetConfigFileValue(profileKey(profile, "config.token_storage")) {
+	case TokenStorageKeyring:
+		token, err := keyring.Get(keyringService, profile)
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", TokenSourceKeyring, nil
+		}
+		if err != nil {
+			return "", TokenSourceKeyring, fmt.Errorf("couldn't read the token from the keyring: %w", err)
+		}
+		return token, TokenSourceKeyring, nil
+	case TokenStorageEncrypted:
+		credentials, err := readCredentials()
+		return credentials[profile], TokenSourceEncrypted, err
+	}
+	return GetConfigFileValue(profileKey(profile, "config.token")), TokenSourceConfig, nil
+}
+
+// StoreToken stores the token of profile in storage, removes it from the
+// other storages, and returns the storage used.
+func StoreToken(profile string, token string, storage string) (string, error) {
+	if storage == TokenStorageAuto {
+		switch {
+		case keyring.Set(keyringService, profile, token) == nil:
+			storage = TokenStorageKeyring
+		case os.Getenv("ASSEMBLYAI_KEYRING_PASSWORD") != "":
+			storage = TokenStorageEncrypted
+		default:
+			fmt.Fprintln(os.Stderr, "No keyring found, storing the token in plain text in the config file. Set ASSEMBLYAI_KEYRING_PASSWORD to encrypt it instead.")
+			storage = TokenStoragePlaintext
+		}
+	}
+
+	switch storage {
+	case TokenStorageKeyring:
+		if err := keyring.Set(keyringService, profile, token); err != nil {
+			return "", fmt.Errorf("couldn't store the token in the keyring: %w", err)
+		}
+	case TokenStorageEncrypted:
+		credentials, err := readCredentials()
+		if err != nil {
+			return "", err
+		}
+		credentials[profile] = token
+		if err := writeCredentials(credentials); err != nil {
+			return "", err
+		}
+	case TokenStoragePlaintext:
+		SetProfileValue(profile, "config.token", token)
+	default:
+		return "", fmt.Errorf("unknown token storage %q, expected one of %s", storage, strings.Join(TokenStorages, ", "))
+	}
+
+	previous := GetConfigFileValue(profileKey(profile, "config.token_storage"))
+	if previous != "" && previous != storage {
+		deleteStoredToken(profile, previous)
+	}
+	if storage == TokenStoragePlaintext {
+		UnsetConfigFileValue(profileKey(profile, "config.token_storage"))
+	} else {
+		UnsetConfigFileValue(profileKey(profile, "config.token"))
+		SetProfileValue(profile, "config.token_storage", storage)
+	}
+	return storage, nil
+}
+
+// deleteStoredToken removes the token of profile from the keyring or the
+// encrypted file. Tokens that are already gone are ignored.
+func deleteStoredToken(profile string, storage string) error {
+	switch storage {
+	case TokenStorageKeyring:
+		if err := keyring.Delete(keyringService, profile); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return err
+		}
+	case TokenStorageEncrypted:
+		credentials, err := readCredentials()
+		if err != nil {
+			return err
+		}
+		if _, ok := credentials[profile]; ok {
+			delete(credentials, profile)
+			return writeCredentials(credentials)
+		}
+	}
+	return nil
+}
+
+// MaskToken hides all but the ends of token, so it can be shown on screen.
+func MaskToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + strings.Repeat("*", len(token)-8) + token[len(token)-4:]
+}
+
+// encryptedCredentials is the content of the credentials file. The tokens
+// are encrypted with AES-GCM, with a key derived from the password with
+// scrypt.
+type encryptedCredentials struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func credentialsFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ConfigFolderPath, CredentialsFileName), nil
+}
+
+// credentialsPassword returns the password of the credentials file from the
+// ASSEMBLYAI_KEYRING_PASSWORD env variable, or asks for it on a terminal.
+func credentialsPassword() ([]byte, error) {
+	if password := os.Getenv("ASSEMBLYAI_KEYRING_PASSWORD"); password != "" {
+		return []byte(password), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("set ASSEMBLYAI_KEYRING_PASSWORD to the password of the encrypted token file")
+	}
+	fmt.Fprint(os.Stderr, "Password of the encrypted token file: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(password) == 0 {
+		return nil, errors.New("the password of the encrypted token file can't be empty")
+	}
+	return password, nil
+}
+
+func credentialsCipher(password []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readCredentials decrypts the tokens of the credentials file, keyed by
+// profile. It returns an empty map when the file doesn't exist.
+func readCredentials() (map[string]string, error) {
+	credentials := map[string]string{}
+	path, err := credentialsFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedCredentials
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("the encrypted token file is corrupted: %w", err)
+	}
+	password, err := credentialsPassword()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := credentialsCipher(password, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, errors.New("the encrypted token file is corrupted")
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, errors.New("wrong password for the encrypted token file")
+	}
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, fmt.Errorf("the encrypted token file is corrupted: %w", err)
+	}
+	return credentials, nil
+}
+
+// writeCredentials encrypts credentials into the credentials file, readable
+// by the user only.
+func writeCredentials(credentials map[string]string) error {
+	path, err := credentialsFile()
+	if err != nil {
+		return err
+	}
+	password, err := credentialsPassword()
+	if err != nil {
+		return err
+	}
+	file := encryptedCredentials{Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	aead, err := credentialsCipher(password, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plaintext, nil)
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestStoreToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ASSEMBLYAI_API_KEY", "")
+	t.Setenv("ASSEMBLYAI_PROFILE", "")
+	t.Setenv("ASSEMBLYAI_KEYRING_PASSWORD", "secret")
+	keyring.MockInit()
+	CreateConfigFile()
+	configFile := filepath.Join(home, ConfigFolderPath, ConfigFileName)
+
+	if storage, err := StoreToken(DefaultProfile, "plain-token", TokenStorageAuto); err != nil || storage != TokenStorageKeyring {
+		t.Fatalf("Expected the keyring to be used, got %s: %v", storage, err)
+	}
+	if token, source, err := LookupToken(); err != nil || token != "plain-token" || source != TokenSourceKeyring {
+		t.Errorf("Expected the token of the keyring, got %q from %s: %v", token, source, err)
+	}
+
+	if _, err := StoreToken(DefaultProfile, "file-token", TokenStorageEncrypted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Get(keyringService, DefaultProfile); err != keyring.ErrNotFound {
+		t.Errorf("Expected the token to be removed from the keyring, got %v.", err)
+	}
+	if token, source, _ := LookupToken(); token != "file-token" || source != TokenSourceEncrypted {
+		t.Errorf("Expected the token of the encrypted file, got %q from %s.", token, source)
+	}
+
+	if _, err := StoreToken(DefaultProfile, "plain-token", TokenStoragePlaintext); err != nil {
+		t.Fatal(err)
+	}
+	if credentials, _ := readCredentials(); len(credentials) != 0 {
+		t.Errorf("Expected the token to be removed from the encrypted file, got %v.", credentials)
+	}
+	data, _ := os.ReadFile(configFile)
+	if !strings.Contains(string(data), "plain-token") || strings.Contains(string(data), "token_storage") {
+		t.Errorf("Expected the token in the config file, got %s.", data)
+	}
+
+	t.Setenv("ASSEMBLYAI_API_KEY", "env-token")
+	if token, source, _ := LookupToken(); token != "env-token" || source != TokenSourceEnv {
+		t.Errorf("Expected the environment to take precedence, got %q from %s.", token, source)
+	}
+	FlagOverrides["config.token"] = "flag-token"
+	defer delete(FlagOverrides, "config.token")
+	if token, source, _ := LookupToken(); token != "flag-token" || source != TokenSourceFlag {
+		t.Errorf("Expected the flag to take precedence, got %q from %s.", token, source)
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	for token, want := range map[string]string{
+		"":                     "",
+		"short":                "*****",
+		"0123456789abcdef0123": "0123************0123",
+	} {
+		if got := MaskToken(token); got != want {
+			t.Errorf("MaskToken(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// ReadIDs reads whitespace separated transcript IDs, ignoring lines starting
+// with "#".
+func ReadIDs(r io.Reader) ([]string, error) {
+	ids := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, strings.Fields(line)...)
+	}
+	return ids, scanner.Err()
+}
+
+// ParseAge parses an age such as "90m", "12h", "30d" or "1d12h". Days can
+// only come first, the rest is read by time.ParseDuration.
+func ParseAge(value string) (time.Duration, error) {
+	var days time.Duration
+	if index := strings.Index(value, "d"); index >= 0 {
+		count, err := strconv.Atoi(value[:index])
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", value)
+		}
+		days = time.Duration(count) * 24 * time.Hour
+		if value = value[index+1:]; value == "" {
+			return days, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err == nil && age < 0 {
+		err = fmt.Errorf("negative age %q", value)
+	}
+	return days + age, err
+}
+
+// Confirm asks a yes/no question on the terminal and defaults to no.
+func Confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func DeleteTranscripts(client *C.Client, ids []string, flags S.DeleteFlags) {
+	ids = unique(ids)
+	if len(ids) == 0 {
+		fmt.Println("No transcripts to delete.")
+		return
+	}
+	if flags.DryRun {
+		fmt.Printf("Would delete %d transcript(s):\n", len(ids))
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+		return
+	}
+	if !flags.Yes && !Confirm(fmt.Sprintf("Delete %d transcript(s)? This can't be undone.", len(ids))) {
+		fmt.Println("Nothing was deleted.")
+		return
+	}
+
+	table := uitable.New()
+	table.Wrap = true
+	table.Separator = " |\t"
+	table.AddRow("| id", "result")
+	var firstErr error
+	deleted := 0
+	for _, id := range ids {
+		_, err := client.DeleteTranscript(context.Background(), id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			table.AddRow("| "+id, "failed: "+apiErrorMessage(err))
+			continue
+		}
+		deleted++
+		RecordDeleted(id)
+		table.AddRow("| "+id, "deleted")
+	}
+	fmt.Println(table)
+	fmt.Println()
+	fmt.Printf("Deleted %d of %d transcript(s).\n", deleted, len(ids))
+
+	if firstErr != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   firstErr,
+			Message: "Some transcripts could not be deleted.",
+		}
+		PrintError(printErrorProps)
+	}
+}
+
+func apiErrorMessage(err error) string {
+	var apiErr *C.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90m", 90 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"2d30m", 48*time.Hour + 30*time.Minute, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"1d-2h", 0, true},
+		{"12h1d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, test := range tests {
+		got, err := ParseAge(test.value)
+		if (err != nil) != test.wantErr || (!test.wantErr && got != test.want) {
+			t.Errorf("ParseAge(%q) = %v, %v, expected %v", test.value, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+)
+
+// Exit codes returned by the CLI. They are documented in the README and must
+// stay stable, since scripts rely on them.
+const (
+	ExitOK             = 0
+	ExitError          = 1
+	ExitAuthentication = 3
+	ExitInvalidRequest = 4
+	ExitRateLimited    = 5
+	ExitServerError    = 6
+	ExitNetworkError   = 7
+	ExitInterrupted    = 130
+)
+
+// ErrNoToken and ErrInvalidToken are reported when the CLI can't authenticate.
+var (
+	ErrNoToken      = errors.New("No token found")
+	ErrInvalidToken = errors.New("Invalid token")
+)
+
+// ExitCode maps an error to the exit code the CLI terminates with.
+func ExitCode(err error) int {
+	var authErr *C.AuthenticationError
+	var invalidErr *C.InvalidRequestError
+	var rateLimitErr *C.RateLimitError
+	var serverErr *C.ServerError
+	var networkErr *C.NetworkError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrNoToken), errors.Is(err, ErrInvalidToken), errors.As(err, &authErr):
+		return ExitAuthentication
+	case errors.As(err, &invalidErr):
+		return ExitInvalidRequest
+	case errors.As(err, &rateLimitErr):
+		return ExitRateLimited
+	case errors.As(err, &serverErr):
+		return ExitServerError
+	case errors.As(err, &networkErr):
+		return ExitNetworkError
+	default:
+		return ExitError
+	}
+}
+
+// errorDetail describes what the API reported, or "" when err did not come
+// from the API client.
+func errorDetail(err error) string {
+	var apiErr *C.APIError
+	var networkErr *C.NetworkError
+	if errors.As(err, &apiErr) {
+		detail := fmt.Sprintf("The API responded with %d: %s", apiErr.StatusCode, apiErr.Message)
+		if apiErr.RequestID != "" {
+			detail += fmt.Sprintf(" (request ID %s)", apiErr.RequestID)
+		}
+		return detail
+	}
+	if errors.As(err, &networkErr) {
+		return "Could not reach the AssemblyAI API. Please check your connection and try again."
+	}
+	return ""
+}
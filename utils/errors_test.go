@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+)
+
+func TestExitCode(t *testing.T) {
+	apiErr := &C.APIError{StatusCode: 400, Message: "bad"}
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{ErrInvalidToken, ExitAuthentication},
+		{&C.AuthenticationError{APIError: apiErr}, ExitAuthentication},
+		{&C.InvalidRequestError{APIError: apiErr}, ExitInvalidRequest},
+		{&C.RateLimitError{APIError: apiErr}, ExitRateLimited},
+		{&C.ServerError{APIError: apiErr}, ExitServerError},
+		{&C.NetworkError{Err: errors.New("connection reset")}, ExitNetworkError},
+		{fmt.Errorf("wrapped: %w", &C.ServerError{APIError: apiErr}), ExitServerError},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("ExitCode(%v) = %d, expected %d.", test.err, code, test.code)
+		}
+	}
+}
+
+func TestErrorDetail(t *testing.T) {
+	err := &C.InvalidRequestError{APIError: &C.APIError{StatusCode: 400, Message: "Invalid language_code", RequestID: "req-1"}}
+	expected := "The API responded with 400: Invalid language_code (request ID req-1)"
+	if detail := errorDetail(err); detail != expected {
+		t.Errorf("Expected %q, got %q.", expected, detail)
+	}
+	if detail := errorDetail(errors.New("boom")); detail != "" {
+		t.Errorf("Expected no detail for non-API errors, got %q.", detail)
+	}
+}
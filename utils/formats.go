@@ -0,0 +1,328 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"golang.org/x/term"
+)
+
+// Formatter renders a completed transcript in one output format.
+type Formatter struct {
+	Name        string
+	Extension   string
+	Description string
+	Render      func(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error
+}
+
+var formatters = map[string]Formatter{}
+
+// RegisterFormatter makes a format available to --format. Registering a name
+// twice replaces the earlier formatter.
+func RegisterFormatter(formatter Formatter) {
+	formatters[formatter.Name] = formatter
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, bool) {
+	formatter, ok := formatters[name]
+	return formatter, ok
+}
+
+// DefaultFormat returns the format of output.format in the project file or
+// the active profile, or text.
+func DefaultFormat() string {
+	if format, ok := projectValue("output.format"); ok {
+		return format
+	}
+	if format := GetProfileValue("output.format"); format != "" {
+		return format
+	}
+	return "text"
+}
+
+// FormatNames returns the registered format names in alphabetical order.
+func FormatNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterFormatter(Formatter{Name: "text", Extension: "txt", Description: "Tables for reading in a terminal", Render: renderText})
+	RegisterFormatter(Formatter{Name: "json", Extension: "json", Description: "The transcript as returned by the API", Render: renderJSON})
+	RegisterFormatter(Formatter{Name: "srt", Extension: "srt", Description: "SubRip captions", Render: renderSrt})
+	RegisterFormatter(Formatter{Name: "vtt", Extension: "vtt", Description: "WebVTT captions", Render: renderVtt})
+	RegisterFormatter(Formatter{Name: "markdown", Extension: "md", Description: "A Markdown document", Render: renderMarkdown})
+	RegisterFormatter(Formatter{Name: "csv", Extension: "csv", Description: "One row per word with timestamps", Render: renderCsv})
+}
+
+// ParseOutputs turns --format values and the -o destination into output
+// targets. A format may name its own destination as format=path. Otherwise
+// it's written to output, which is a file, a directory (existing or ending in
+// a separator) that gets an [id].[extension] file per format, or "-" or ""
+// for stdout. Only one format can be written to stdout or a single file.
+func ParseOutputs(formats []string, output string) ([]S.OutputTarget, error) {
+	targets := []S.OutputTarget{}
+	shared := 0
+	for _, value := range formats {
+		name, path, hasPath := strings.Cut(strings.TrimSpace(value), "=")
+		if _, ok := LookupFormatter(name); !ok {
+			return nil, fmt.Errorf("unknown format %q, expected one of %s", name, strings.Join(FormatNames(), ", "))
+		}
+		if !hasPath {
+			path = output
+			if !isOutputDir(path) {
+				shared++
+			}
+		}
+		targets = append(targets, S.OutputTarget{Format: name, Path: path})
+	}
+	if shared > 1 {
+		if output == "" || output == "-" {
+			return nil, errors.New("only one format can be written to stdout, use -o with a directory or format=path")
+		}
+		return nil, fmt.Errorf("only one format can be written to %s, use -o with a directory or format=path", output)
+	}
+	return targets, nil
+}
+
+func isOutputDir(path string) bool {
+	if path == "" || path == "-" {
+		return false
+	}
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// WriteOutputs renders transcript once per target. Files are reported on
+// stderr so stdout only ever holds the requested output.
+func WriteOutputs(transcript S.TranscriptResponse, flags S.TranscribeFlags) {
+	for _, target := range flags.Outputs {
+		formatter, _ := LookupFormatter(target.Format)
+		if target.Path == "" || target.Path == "-" {
+			if err := formatter.Render(os.Stdout, transcript, flags); err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: fmt.Sprintf("Could not render the transcript as %s.", target.Format),
+				}
+				PrintError(printErrorProps)
+			}
+			continue
+		}
+		path := target.Path
+		if isOutputDir(path) {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				printErrorProps := S.PrintErrorProps{
+					Error:   err,
+					Message: fmt.Sprintf("Could not create directory %s, please check your permissions.", path),
+				}
+				PrintError(printErrorProps)
+				return
+			}
+			path = filepath.Join(path, fmt.Sprintf("%s.%s", *transcript.ID, formatter.Extension))
+		}
+		if err := writeOutputFile(path, formatter, transcript, flags); err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: err.Error() + ".",
+			}
+			PrintError(printErrorProps)
+			return
+		}
+	}
+}
+
+func writeOutputFile(path string, formatter Formatter, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	if err := renderFile(path, formatter, transcript, flags); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Successfully created file %s\n", path)
+	return nil
+}
+
+// renderFile writes transcript to path in the formatter's format.
+func renderFile(path string, formatter Formatter, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Could not create %s, please check your permissions", path)
+	}
+	if err := formatter.Render(f, transcript, flags); err != nil {
+		f.Close()
+		return fmt.Errorf("Could not write to %s: %v", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Could not write to %s: %v", path, err)
+	}
+	return nil
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 512 when
+// it isn't one, so tables aren't wrapped in files and pipes.
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if getWidth, _, err := term.GetSize(int(f.Fd())); err == nil {
+			return getWidth
+		}
+	}
+	return 512
+}
+
+func renderText(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	width := terminalWidth(w)
+	heading := func(title string) {
+		if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			fmt.Fprintf(w, "\033[1m%s\033[0m\n", title)
+			return
+		}
+		fmt.Fprintln(w, title)
+	}
+
+	heading("Transcript")
+	if transcript.Segments != nil {
+		segmentsPrintFormatted(w, width, *transcript.Segments, transcript.DualChannel != nil && *transcript.DualChannel)
+	} else if transcript.SpeakerLabels == true {
+		speakerLabelsPrintFormatted(w, width, transcript.Utterances)
+	} else {
+		textPrintFormatted(w, width, *transcript.Text, transcript.Words)
+	}
+	if transcript.DualChannel != nil && *transcript.DualChannel == true {
+		heading("\nDual Channel")
+		dualChannelPrintFormatted(w, width, transcript.Utterances)
+	}
+	if transcript.AutoHighlights != nil && *transcript.AutoHighlights == true {
+		heading("Highlights")
+		highlightsPrintFormatted(w, transcript.AutoHighlightsResult)
+	}
+	if transcript.ContentSafety != nil && *transcript.ContentSafety == true {
+		heading("Content Moderation")
+		contentSafetyPrintFormatted(w, width, transcript.ContentSafetyLabels)
+	}
+	if transcript.IabCategories != nil && *transcript.IabCategories == true {
+		heading("Topic Detection")
+		topicDetectionPrintFormatted(w, width, transcript.IabCategoriesResult)
+	}
+	if transcript.SentimentAnalysis != nil && *transcript.SentimentAnalysis == true {
+		heading("Sentiment Analysis")
+		sentimentAnalysisPrintFormatted(w, width, transcript.SentimentAnalysisResults)
+	}
+	if transcript.AutoChapters != nil && *transcript.AutoChapters == true {
+		heading("Chapters")
+		chaptersPrintFormatted(w, width, transcript.Chapters)
+	}
+	if transcript.EntityDetection != nil && *transcript.EntityDetection == true {
+		heading("Entity Detection")
+		entityDetectionPrintFormatted(w, width, transcript.Entities)
+	}
+	if transcript.Summarization != nil && *transcript.Summarization == true {
+		heading("Summary")
+		summaryPrintFormatted(w, width, *transcript.Summary)
+	}
+	return nil
+}
+
+func renderJSON(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	_, err := fmt.Fprintln(w, string(BeutifyJSON(transcript.Raw)))
+	return err
+}
+
+func renderSrt(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	_, err := io.WriteString(w, GetSrtText(CaptionWords(transcript), flags.Captions))
+	return err
+}
+
+func renderVtt(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	_, err := io.WriteString(w, GetVttText(CaptionWords(transcript), flags.Captions))
+	return err
+}
+
+func renderMarkdown(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Transcript %s\n\n", *transcript.ID)
+	isDualChannel := transcript.DualChannel != nil && *transcript.DualChannel
+	if transcript.Utterances != nil && (transcript.SpeakerLabels || isDualChannel) {
+		for _, utterance := range *transcript.Utterances {
+			voice := "Speaker " + utterance.Speaker
+			if isDualChannel {
+				voice = "Channel " + utterance.Channel
+			}
+			start := ""
+			if utterance.Start != nil {
+				start = " (" + TransformMsToTimestamp(*utterance.Start, false) + ")"
+			}
+			fmt.Fprintf(&b, "**%s**%s: %s\n\n", voice, start, utterance.Text)
+		}
+	} else if transcript.Text != nil {
+		fmt.Fprintf(&b, "%s\n\n", *transcript.Text)
+	}
+
+	if transcript.Summary != nil {
+		if summary, ok := (*transcript.Summary).(string); ok && summary != "" {
+			fmt.Fprintf(&b, "## Summary\n\n%s\n\n", summary)
+		}
+	}
+	if transcript.Chapters != nil && len(*transcript.Chapters) > 0 {
+		b.WriteString("## Chapters\n\n")
+		for _, chapter := range *transcript.Chapters {
+			fmt.Fprintf(&b, "### %s (%s-%s)\n\n%s\n\n", chapter.Headline, TransformMsToTimestamp(*chapter.Start, false), TransformMsToTimestamp(*chapter.End, false), chapter.Summary)
+		}
+	}
+	if transcript.AutoHighlightsResult != nil && len(transcript.AutoHighlightsResult.Results) > 0 {
+		b.WriteString("## Highlights\n\n")
+		for _, highlight := range transcript.AutoHighlightsResult.Results {
+			fmt.Fprintf(&b, "- %s\n", highlight.Text)
+		}
+		b.WriteString("\n")
+	}
+	if transcript.Entities != nil && len(*transcript.Entities) > 0 {
+		b.WriteString("## Entities\n\n")
+		seen := map[string]bool{}
+		for _, entity := range *transcript.Entities {
+			line := fmt.Sprintf("- %s: %s\n", entity.EntityType, entity.Text)
+			if !seen[line] {
+				seen[line] = true
+				b.WriteString(line)
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+func renderCsv(w io.Writer, transcript S.TranscriptResponse, flags S.TranscribeFlags) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"start", "end", "speaker", "confidence", "text"})
+	for _, word := range CaptionWords(transcript) {
+		start, end, confidence := "", "", ""
+		if word.Start != nil {
+			start = strconv.FormatInt(*word.Start, 10)
+		}
+		if word.End != nil {
+			end = strconv.FormatInt(*word.End, 10)
+		}
+		if word.Confidence != nil {
+			confidence = strconv.FormatFloat(*word.Confidence, 'f', -1, 64)
+		}
+		writer.Write([]string{start, end, word.Speaker, confidence, word.Text})
+	}
+	writer.Flush()
+	return writer.Error()
+}
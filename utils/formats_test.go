@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestParseOutputs(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		formats []string
+		output  string
+		want    []S.OutputTarget
+		wantErr bool
+	}{
+		{"stdout", []string{"text"}, "", []S.OutputTarget{{Format: "text"}}, false},
+		{"dash", []string{"json"}, "-", []S.OutputTarget{{Format: "json", Path: "-"}}, false},
+		{"file", []string{"srt"}, "out.srt", []S.OutputTarget{{Format: "srt", Path: "out.srt"}}, false},
+		{"existing directory", []string{"srt", "vtt"}, dir, []S.OutputTarget{{Format: "srt", Path: dir}, {Format: "vtt", Path: dir}}, false},
+		{"new directory", []string{"srt", "vtt"}, "subs/", []S.OutputTarget{{Format: "srt", Path: "subs/"}, {Format: "vtt", Path: "subs/"}}, false},
+		{"own paths", []string{"text", "srt=a.srt", "vtt=b.vtt"}, "", []S.OutputTarget{{Format: "text"}, {Format: "srt", Path: "a.srt"}, {Format: "vtt", Path: "b.vtt"}}, false},
+		{"two on stdout", []string{"text", "json"}, "", nil, true},
+		{"two in one file", []string{"srt", "vtt"}, "out.txt", nil, true},
+		{"unknown", []string{"docx"}, "", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseOutputs(test.formats, test.output)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error=%v, got %v", test.wantErr, err)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	transcript := loadTranscript(t)
+	for _, name := range []string{"markdown", "csv"} {
+		formatter, _ := LookupFormatter(name)
+		var buf bytes.Buffer
+		if err := formatter.Render(&buf, transcript, S.TranscribeFlags{Captions: DefaultCaptionOptions}); err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, name, buf.Bytes())
+	}
+}
+
+func TestMarkdownWithoutSummaryText(t *testing.T) {
+	transcript := loadTranscript(t)
+	var bullets interface{} = []interface{}{"First point", "Second point"}
+	transcript.Summary = &bullets
+	var buf bytes.Buffer
+	if err := renderMarkdown(&buf, transcript, S.TranscribeFlags{}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "## Summary") || !strings.HasSuffix(out, "\n") {
+		t.Errorf("Expected no summary section and a final newline, got %q", out)
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	transcript := loadTranscript(t)
+	dir := t.TempDir()
+	flags := S.TranscribeFlags{
+		Captions: DefaultCaptionOptions,
+		Outputs:  []S.OutputTarget{{Format: "srt", Path: dir}, {Format: "json", Path: filepath.Join(dir, "raw.json")}},
+	}
+	WriteOutputs(transcript, flags)
+
+	srt, err := os.ReadFile(filepath.Join(dir, *transcript.ID+".srt"))
+	if err != nil || string(srt) != GetSrtText(CaptionWords(transcript), DefaultCaptionOptions) {
+		t.Errorf("Expected the SRT file in the directory, got %v: %s", err, srt)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "raw.json")); err != nil {
+		t.Errorf("Expected the JSON file: %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+	bolt "go.etcd.io/bbolt"
+)
+
+// HistoryFileName is the database in the config folder that keeps every
+// transcript submitted or fetched from this machine.
+var HistoryFileName = "history.db"
+
+var historyBucket = []byte("transcripts")
+
+var historyWarning sync.Once
+
+// historyKey is the key of the transcript id in the history. Entries are
+// scoped to the API host and profile they were recorded with, so a transcript
+// of another account or host is never served from the history.
+func historyKey(id string) []byte {
+	return append(historyPrefix(), id...)
+}
+
+func historyPrefix() []byte {
+	return []byte(baseURL() + "\x00" + ActiveProfile() + "\x00")
+}
+
+// forEachHistoryEntry calls fn with the data of every entry of the current
+// scope in bucket.
+func forEachHistoryEntry(bucket *bolt.Bucket, fn func(data []byte) error) error {
+	prefix := historyPrefix()
+	cursor := bucket.Cursor()
+	for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func historyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ConfigFolderPath, HistoryFileName), nil
+}
+
+// openHistory opens the history database. It's opened for a single operation
+// at a time, so several assemblyai processes can share it, and waits a little
+// when another one is using it.
+func openHistory() (*bolt.DB, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return bolt.Open(path, 0600, &bolt.Options{Timeout: 2 * time.Second})
+}
+
+// updateHistory changes the entry of the transcript id. A missing entry is
+// created when create is true and left alone otherwise.
+func updateHistory(id string, create bool, update func(entry *S.HistoryEntry)) error {
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(historyBucket)
+		if err != nil {
+			return err
+		}
+		entry := S.HistoryEntry{ID: id}
+		data := bucket.Get(historyKey(id))
+		if data == nil && !create {
+			return nil
+		}
+		if data != nil {
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+		}
+		update(&entry)
+		data, err = json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(historyKey(id), data)
+	})
+}
+
+// recordHistory is updateHistory for commands that shouldn't fail because of
+// the history. Only the first error is reported, on stderr.
+func recordHistory(id string, create bool, update func(entry *S.HistoryEntry)) {
+	if err := updateHistory(id, create, update); err != nil {
+		historyWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "Could not update the local history: %v\n", err)
+		})
+	}
+}
+
+// RecordSubmitted adds a transcript submitted from source with params to the
+// history, without the value of its webhook header, which is a secret.
+func RecordSubmitted(source string, params S.TranscribeParams, transcript S.TranscriptResponse) {
+	params.WebhookAuthHeaderValue = ""
+	now := time.Now().UTC()
+	recordHistory(*transcript.ID, true, func(entry *S.HistoryEntry) {
+		entry.Source = source
+		entry.Params = &params
+		entry.Submitted = &now
+		if transcript.Status != nil {
+			entry.Status = *transcript.Status
+		}
+	})
+}
+
+// RecordFetched saves the latest state of a transcript in the history.
+func RecordFetched(transcript S.TranscriptResponse) {
+	now := time.Now().UTC()
+	recordHistory(*transcript.ID, true, func(entry *S.HistoryEntry) {
+		entry.Fetched = &now
+		if transcript.Status != nil {
+			entry.Status = *transcript.Status
+		}
+		entry.Transcript = transcript.Raw
+	})
+}
+
+// RecordDeleted drops the cached copy of a deleted transcript, keeping only
+// the record that it was submitted.
+func RecordDeleted(id string) {
+	recordHistory(id, false, func(entry *S.HistoryEntry) {
+		entry.Status = "deleted"
+		entry.Transcript = nil
+	})
+}
+
+// CachedTranscript returns the transcript id from the history if it's
+// completed there. A missing database is never created.
+func CachedTranscript(id string) (*S.TranscriptResponse, bool) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, false
+	}
+	db, err := openHistory()
+	if err != nil {
+		return nil, false
+	}
+	defer db.Close()
+
+	var entry S.HistoryEntry
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+		if bucket == nil {
+			return errors.New("empty history")
+		}
+		data := bucket.Get(historyKey(id))
+		if data == nil {
+			return errors.New("not in history")
+		}
+		return json.Unmarshal(data, &entry)
+	})
+	if err != nil || entry.Status != "completed" || len(entry.Transcript) == 0 {
+		return nil, false
+	}
+	var transcript S.TranscriptResponse
+	if err := json.Unmarshal(entry.Transcript, &transcript); err != nil {
+		return nil, false
+	}
+	transcript.Raw = entry.Transcript
+	return &transcript, true
+}
+
+// cachedTranscripts calls fn with every completed transcript of the history,
+// most recent first.
+func cachedTranscripts(fn func(entry S.HistoryEntry, transcript S.TranscriptResponse)) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	entries := []S.HistoryEntry{}
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+		if bucket == nil {
+			return nil
+		}
+		return forEachHistoryEntry(bucket, func(data []byte) error {
+			var entry S.HistoryEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			if entry.Status == "completed" && len(entry.Transcript) > 0 {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return historyTime(entries[i]).After(historyTime(entries[j]))
+	})
+	for _, entry := range entries {
+		var transcript S.TranscriptResponse
+		if err := json.Unmarshal(entry.Transcript, &transcript); err != nil {
+			continue
+		}
+		fn(entry, transcript)
+	}
+	return nil
+}
+
+// ShowCachedTranscript renders the transcript id from the history, and
+// reports whether it was there. Sentences and paragraphs aren't cached, so
+// they are still fetched from the API with the stored token.
+func ShowCachedTranscript(id string, flags S.TranscribeFlags) bool {
+	transcript, ok := CachedTranscript(id)
+	if !ok {
+		return false
+	}
+	if flags.Segmentation != "" {
+		if err := fetchSegments(context.Background(), NewClient(Token), transcript, flags.Segmentation); err != nil {
+			printSegmentsError(err, flags.Segmentation)
+			return true
+		}
+	}
+	WriteOutputs(*transcript, flags)
+	return true
+}
+
+// HistoryEntries returns the history, most recent first, without the cached
+// transcripts.
+func HistoryEntries(flags S.HistoryFlags) ([]S.HistoryEntry, error) {
+	entries := []S.HistoryEntry{}
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return entries, nil
+	}
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+		if bucket == nil {
+			return nil
+		}
+		return forEachHistoryEntry(bucket, func(data []byte) error {
+			var entry S.HistoryEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			if flags.Status == "" || entry.Status == flags.Status {
+				entry.Transcript = nil
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return historyTime(entries[i]).After(historyTime(entries[j]))
+	})
+	if flags.Limit > 0 && len(entries) > flags.Limit {
+		entries = entries[:flags.Limit]
+	}
+	return entries, nil
+}
+
+// historyTime is when a transcript was submitted, or first seen when it was
+// submitted from elsewhere.
+func historyTime(entry S.HistoryEntry) time.Time {
+	if entry.Submitted != nil {
+		return *entry.Submitted
+	}
+	if entry.Fetched != nil {
+		return *entry.Fetched
+	}
+	return time.Time{}
+}
+
+func ShowHistory(flags S.HistoryFlags) {
+	entries, err := HistoryEntries(flags)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't read the local history.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	if flags.Json {
+		data, _ := json.Marshal(entries)
+		fmt.Println(string(BeutifyJSON(data)))
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "No transcripts in the history.")
+		return
+	}
+	historyPrintFormatted(os.Stdout, entries)
+}
+
+func historyPrintFormatted(w io.Writer, entries []S.HistoryEntry) {
+	table := uitable.New()
+	table.Separator = " |\t"
+	table.AddRow("| id", "status", "date", "source")
+	for _, entry := range entries {
+		date := "-"
+		if when := historyTime(entry); !when.IsZero() {
+			date = when.Local().Format("2006-01-02 15:04")
+		}
+		source := entry.Source
+		if source == "" {
+			source = "-"
+		}
+		table.AddRow("| "+entry.ID, entry.Status, date, source)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if _, ok := CachedTranscript("tr_golden"); ok {
+		t.Fatal("Expected an empty history.")
+	}
+	RecordDeleted("tr_unknown")
+
+	transcript := loadTranscript(t)
+	queued := "queued"
+	RecordSubmitted("/calls/a.mp3", S.TranscribeParams{SpeakerLabels: true}, S.TranscriptResponse{ID: transcript.ID, Status: &queued})
+	if _, ok := CachedTranscript("tr_golden"); ok {
+		t.Error("Expected a queued transcript not to be cached.")
+	}
+	RecordFetched(transcript)
+	cached, ok := CachedTranscript("tr_golden")
+	if !ok {
+		t.Fatal("Expected the completed transcript to be cached.")
+	}
+	var want, got interface{}
+	json.Unmarshal(transcript.Raw, &want)
+	json.Unmarshal(cached.Raw, &got)
+	if *cached.Text != *transcript.Text || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the cached transcript to match the API response, got %s", cached.Raw)
+	}
+
+	other := "tr_other"
+	RecordFetched(S.TranscriptResponse{ID: &other, Status: &queued, Raw: []byte(`{"id": "tr_other"}`)})
+	entries, err := HistoryEntries(S.HistoryFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "tr_other" || entries[1].Source != "/calls/a.mp3" || !entries[1].Params.SpeakerLabels || entries[1].Transcript != nil {
+		t.Errorf("Expected both transcripts, most recent first and without their content, got %+v", entries)
+	}
+	if entries, _ := HistoryEntries(S.HistoryFlags{Limit: 1, Status: "completed"}); len(entries) != 1 || entries[0].ID != "tr_golden" {
+		t.Errorf("Expected the completed transcript only, got %+v", entries)
+	}
+
+	t.Setenv("ASSEMBLYAI_BASE_URL", "https://staging.example.com")
+	if _, ok := CachedTranscript("tr_golden"); ok {
+		t.Error("Expected the history of another API host not to be used.")
+	}
+	if entries, _ := HistoryEntries(S.HistoryFlags{}); len(entries) != 0 {
+		t.Errorf("Expected the history of another API host to be hidden, got %+v", entries)
+	}
+	os.Unsetenv("ASSEMBLYAI_BASE_URL")
+
+	RecordDeleted("tr_golden")
+	if _, ok := CachedTranscript("tr_golden"); ok {
+		t.Error("Expected a deleted transcript not to be cached.")
+	}
+	if entries, _ := HistoryEntries(S.HistoryFlags{Status: "deleted"}); len(entries) != 1 || entries[0].Source != "/calls/a.mp3" {
+		t.Errorf("Expected the deleted transcript to stay in the history, got %+v", entries)
+	}
+	if entries, _ := HistoryEntries(S.HistoryFlags{}); len(entries) != 2 {
+		t.Errorf("Expected deleting an unknown transcript not to add it, got %+v", entries)
+	}
+}
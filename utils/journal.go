@@ -0,0 +1,268 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/google/uuid"
+	"github.com/gosuri/uitable"
+)
+
+// JournalFolderName is the folder of the config folder batch journals are
+// saved in.
+var JournalFolderName = "jobs"
+
+// Journal records the progress of a batch in a file, saved after every step
+// of every job, so an interrupted batch can be resumed without uploading or
+// submitting files again.
+type Journal struct {
+	mu         sync.Mutex
+	path       string
+	data       S.BatchJournal
+	saveFailed bool
+}
+
+func journalFolder() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ConfigFolderPath, JournalFolderName), nil
+}
+
+// NewJournal creates the journal of a new batch. Local paths are made
+// absolute so the batch can be resumed from any folder.
+func NewJournal(jobs []S.BatchJob, flags S.BatchFlags) (*Journal, error) {
+	now := time.Now()
+	journal := &Journal{data: S.BatchJournal{
+		ID:      now.Format("20060102-150405") + "-" + uuid.New().String()[:8],
+		Created: now.UTC(),
+		Flags:   flags,
+	}}
+	if outputDir, err := filepath.Abs(flags.OutputDir); err == nil {
+		journal.data.Flags.OutputDir = outputDir
+	}
+	for _, job := range jobs {
+		if !isUrl(job.Source) {
+			if source, err := filepath.Abs(job.Source); err == nil {
+				job.Source = source
+			}
+		}
+		journal.data.Items = append(journal.data.Items, S.JournalItem{Job: job, Status: "pending"})
+	}
+
+	folder, err := journalFolder()
+	if err != nil {
+		return journal, err
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return journal, err
+	}
+	journal.path = filepath.Join(folder, journal.data.ID+".json")
+	return journal, journal.save()
+}
+
+// LoadJournal reads the journal of the batch id.
+func LoadJournal(id string) (*Journal, error) {
+	folder, err := journalFolder()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(folder, filepath.Base(id)+".json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("there's no batch job %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	journal := &Journal{path: path}
+	if err := json.Unmarshal(data, &journal.data); err != nil {
+		return nil, fmt.Errorf("the journal %s is corrupted: %w", path, err)
+	}
+	return journal, nil
+}
+
+// ListJournals returns the saved batches, newest first.
+func ListJournals() ([]S.BatchJournal, error) {
+	folder, err := journalFolder()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(folder)
+	if os.IsNotExist(err) {
+		return []S.BatchJournal{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	journals := []S.BatchJournal{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		journal, err := LoadJournal(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		journals = append(journals, journal.data)
+	}
+	sort.Slice(journals, func(i, j int) bool {
+		return journals[i].Created.After(journals[j].Created)
+	})
+	return journals, nil
+}
+
+// ID returns the batch's ID, used to resume it.
+func (j *Journal) ID() string {
+	return j.data.ID
+}
+
+// Flags returns the options the batch runs with.
+func (j *Journal) Flags() S.BatchFlags {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.data.Flags
+}
+
+// SetPoll sets whether the batch waits for transcripts to complete.
+func (j *Journal) SetPoll(poll bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.data.Flags.Poll = poll
+}
+
+// Len returns the number of jobs in the batch.
+func (j *Journal) Len() int {
+	return len(j.data.Items)
+}
+
+// Item returns a copy of the state of a job.
+func (j *Journal) Item(index int) S.JournalItem {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.data.Items[index]
+}
+
+// Update changes the state of a job and saves the journal. The first failure
+// to save is reported on stderr, the batch then carries on without it.
+func (j *Journal) Update(index int, update func(item *S.JournalItem)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	update(&j.data.Items[index])
+	if err := j.save(); err != nil && !j.saveFailed {
+		j.saveFailed = true
+		fmt.Fprintf(os.Stderr, "Could not save the batch journal %s: %v\n", j.path, err)
+	}
+}
+
+// WebhookAuthHeaderName returns the webhook header of the jobs that still have
+// to be submitted but lost its value, which isn't saved in the journal, or ""
+// when none did.
+func (j *Journal) WebhookAuthHeaderName() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, item := range j.data.Items {
+		params := item.Job.Params
+		if item.ID == "" && params.WebhookAuthHeaderName != "" && params.WebhookAuthHeaderValue == "" {
+			return params.WebhookAuthHeaderName
+		}
+	}
+	return ""
+}
+
+// SetWebhookAuthHeaderValue gives the value of their webhook header back to
+// the jobs that lost it.
+func (j *Journal) SetWebhookAuthHeaderValue(value string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i := range j.data.Items {
+		params := &j.data.Items[i].Job.Params
+		if params.WebhookAuthHeaderName != "" && params.WebhookAuthHeaderValue == "" {
+			params.WebhookAuthHeaderValue = value
+		}
+	}
+}
+
+// save writes the journal to a temporary file first, so an interruption never
+// leaves it half written. A journal without a path is only kept in memory.
+// The values of webhook headers are secrets, so they're left out and have to
+// be given again to resume the batch.
+func (j *Journal) save() error {
+	if j.path == "" {
+		return nil
+	}
+	journal := j.data
+	journal.Items = append([]S.JournalItem(nil), j.data.Items...)
+	for i := range journal.Items {
+		journal.Items[i].Job.Params.WebhookAuthHeaderValue = ""
+	}
+	data, err := json.MarshalIndent(journal, "", "\t")
+	if err != nil {
+		return err
+	}
+	temp := j.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, j.path)
+}
+
+// IsJournalItemDone reports whether a job needs no more work: its transcript
+// either failed or completed with every output written.
+func IsJournalItemDone(item S.JournalItem) bool {
+	return item.Status == "error" || (item.Status == "completed" && item.Error == "")
+}
+
+// ListBatches prints the saved batches with how many of their jobs are done.
+func ListBatches() {
+	journals, err := ListJournals()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't read the batch jobs.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	if len(journals) == 0 {
+		fmt.Fprintln(os.Stderr, "No batch jobs found.")
+		return
+	}
+	batchListPrintFormatted(os.Stdout, journals)
+}
+
+func batchListPrintFormatted(w io.Writer, journals []S.BatchJournal) {
+	table := uitable.New()
+	table.Separator = " |\t"
+	table.AddRow("| job", "created", "done", "failed")
+	for _, journal := range journals {
+		done, failed := 0, 0
+		for _, item := range journal.Items {
+			if IsJournalItemDone(item) && item.Error == "" {
+				done++
+			} else if item.Error != "" {
+				failed++
+			}
+		}
+		table.AddRow(
+			"| "+journal.ID,
+			journal.Created.Local().Format("2006-01-02 15:04"),
+			fmt.Sprintf("%d/%d", done, len(journal.Items)),
+			failed,
+		)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestJournal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	jobs := NewBatchJobs([]string{"calls/a.mp3", "https://example.com/b.mp3"}, S.TranscribeParams{Punctuate: true})
+
+	journal, err := NewJournal(jobs, S.BatchFlags{Poll: true, Concurrency: 2, OutputDir: "out", Formats: []string{"text"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if item := journal.Item(0); item.Job.Source != filepath.Join(wd, "calls/a.mp3") || item.Status != "pending" {
+		t.Errorf("Expected an absolute pending source, got %+v", item)
+	}
+	if journal.Item(1).Job.Source != "https://example.com/b.mp3" {
+		t.Errorf("Expected URLs to be kept, got %s", journal.Item(1).Job.Source)
+	}
+	journal.Update(0, func(item *S.JournalItem) {
+		item.UploadURL = "https://cdn.example.com/a"
+		item.Status = "uploaded"
+	})
+
+	loaded, err := LoadJournal(journal.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Flags().OutputDir != filepath.Join(wd, "out") || !loaded.Item(0).Job.Params.Punctuate {
+		t.Errorf("Expected the flags and parameters to be saved, got %+v", loaded.data)
+	}
+	if item := loaded.Item(0); item.UploadURL != "https://cdn.example.com/a" || item.Status != "uploaded" {
+		t.Errorf("Expected the update to be saved, got %+v", item)
+	}
+	if journals, err := ListJournals(); err != nil || len(journals) != 1 || journals[0].ID != journal.ID() {
+		t.Errorf("Expected the journal to be listed, got %v %v", journals, err)
+	}
+	if _, err := LoadJournal("missing"); err == nil {
+		t.Error("Expected an error for a missing journal.")
+	}
+}
+
+func TestIsJournalItemDone(t *testing.T) {
+	tests := []struct {
+		item S.JournalItem
+		done bool
+	}{
+		{S.JournalItem{Status: "pending"}, false},
+		{S.JournalItem{Status: "uploaded"}, false},
+		{S.JournalItem{Status: "processing", ID: "tr_1"}, false},
+		{S.JournalItem{Status: "completed", ID: "tr_1"}, true},
+		{S.JournalItem{Status: "completed", ID: "tr_1", Error: "Could not create out/a.txt"}, false},
+		{S.JournalItem{Status: "error", ID: "tr_1", Error: "Transcoding failed."}, true},
+	}
+	for _, test := range tests {
+		if IsJournalItemDone(test.item) != test.done {
+			t.Errorf("Expected %+v to be done=%v", test.item, test.done)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// LemurTranscriptIDs returns ids followed by the completed transcripts of the
+// batch job, if any, without duplicates.
+func LemurTranscriptIDs(ids []string, batch string) ([]string, error) {
+	if batch != "" {
+		journal, err := LoadJournal(batch)
+		if err != nil {
+			return nil, err
+		}
+		completed := 0
+		for index := 0; index < journal.Len(); index++ {
+			item := journal.Item(index)
+			if item.Status == "completed" && item.ID != "" && item.Error == "" {
+				ids = append(ids, item.ID)
+				completed++
+			}
+		}
+		if completed == 0 {
+			return nil, fmt.Errorf("batch job %s has no completed transcripts", batch)
+		}
+	}
+	ids = unique(ids)
+	if len(ids) == 0 {
+		return nil, errors.New("no transcript IDs provided")
+	}
+	return ids, nil
+}
+
+// ReadQuestions reads one question per line, ignoring blank lines and lines
+// starting with "#".
+func ReadQuestions(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	questions := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		questions = append(questions, line)
+	}
+	return questions, nil
+}
+
+// RunLemur sends params to the LeMUR endpoint, one of "task", "summary",
+// "question-answer" or "action-items", and prints the response.
+func RunLemur(client *C.Client, endpoint string, params S.LemurParams, flags S.LemurFlags) {
+	generate := map[string]func(context.Context, S.LemurParams) (*S.LemurResponse, error){
+		"task":            client.LemurTask,
+		"summary":         client.LemurSummary,
+		"question-answer": client.LemurQuestionAnswer,
+		"action-items":    client.LemurActionItems,
+	}[endpoint]
+
+	s := CallSpinner(fmt.Sprintf(" Running LeMUR over %d transcript(s)...", len(params.TranscriptIDs)))
+	response, err := generate(context.Background(), params)
+	s.Stop()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "LeMUR couldn't process the request: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	if flags.Json {
+		fmt.Println(string(BeutifyJSON(response.Raw)))
+		return
+	}
+	width := terminalWidth(os.Stdout)
+	lemurPrintFormatted(os.Stdout, width, response)
+}
+
+func lemurPrintFormatted(w io.Writer, width int, response *S.LemurResponse) {
+	table := uitable.New()
+	table.Wrap = true
+	table.MaxColWidth = uint(width - 20)
+	table.Separator = " |\t"
+
+	switch result := response.Response.(type) {
+	case []S.LemurAnswer:
+		for _, answer := range result {
+			table.AddRow("| Question", answer.Question)
+			table.AddRow("| Answer", answer.Answer)
+			table.AddRow("", "")
+		}
+	case string:
+		for _, paragraph := range strings.Split(strings.TrimSpace(result), "\n") {
+			table.AddRow(paragraph)
+		}
+	default:
+		fmt.Fprintln(w, "Could not retrieve the LeMUR response")
+		return
+	}
+
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+	if response.RequestID != "" {
+		fmt.Fprintf(os.Stderr, "LeMUR request ID: %s\n", response.RequestID)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// createdLayout is how the API formats the creation time of transcripts, in UTC.
+const createdLayout = "2006-01-02T15:04:05.999999"
+
+// ParseCreated parses the creation time of a transcript.
+func ParseCreated(created string) (time.Time, error) {
+	return time.Parse(createdLayout, created)
+}
+
+// ParseDate parses a date given on the command line, either as YYYY-MM-DD or
+// as an RFC 3339 timestamp.
+func ParseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// FindTranscripts walks the transcript listing and returns the transcripts
+// created between flags.After and flags.Before, up to flags.Limit of them.
+func FindTranscripts(client *C.Client, params S.TranscriptListParams, flags S.ListFlags) ([]S.TranscriptListItem, error) {
+	transcripts := []S.TranscriptListItem{}
+	err := client.ListAllTranscripts(context.Background(), params, func(transcript S.TranscriptListItem) bool {
+		if created, err := ParseCreated(transcript.Created); err == nil {
+			if !flags.Before.IsZero() && !created.Before(flags.Before) {
+				return true
+			}
+			// The listing is sorted newest first, so nothing after this
+			// one can match.
+			if !flags.After.IsZero() && created.Before(flags.After) {
+				return false
+			}
+		}
+		transcripts = append(transcripts, transcript)
+		return flags.Limit <= 0 || len(transcripts) < flags.Limit
+	})
+	return transcripts, err
+}
+
+func ListTranscripts(client *C.Client, params S.TranscriptListParams, flags S.ListFlags) {
+	transcripts, err := FindTranscripts(client, params, flags)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't list your transcripts.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	if flags.IdsOnly {
+		for _, transcript := range transcripts {
+			fmt.Println(transcript.ID)
+		}
+		return
+	}
+	if flags.Json {
+		data, _ := json.Marshal(transcripts)
+		fmt.Println(string(BeutifyJSON(data)))
+		return
+	}
+	if len(transcripts) == 0 {
+		fmt.Fprintln(os.Stderr, "No transcripts found.")
+		return
+	}
+	transcriptListPrintFormatted(os.Stdout, transcripts)
+}
+
+func transcriptListPrintFormatted(w io.Writer, transcripts []S.TranscriptListItem) {
+	table := uitable.New()
+	table.Separator = " |\t"
+	table.AddRow("| id", "status", "created", "duration", "audio url")
+	for _, transcript := range transcripts {
+		created := transcript.Created
+		if date, err := ParseCreated(transcript.Created); err == nil {
+			created = date.Local().Format("2006-01-02 15:04")
+		}
+		duration := "-"
+		if transcript.AudioDuration != nil {
+			duration = TransformMsToTimestamp(*transcript.AudioDuration*1000, false)
+		}
+		table.AddRow("| "+transcript.ID, transcript.Status, created, duration, transcript.AudioURL)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+}
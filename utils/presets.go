@@ -0,0 +1,207 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// Where a preset is stored.
+const (
+	PresetSourceConfig  = "config"
+	PresetSourceProject = "project"
+)
+
+// ValidatePresetName reports names that can't be used as a config key.
+func ValidatePresetName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("preset names may only contain lowercase letters, digits, - and _, got %q", name)
+	}
+	return nil
+}
+
+// Presets returns the presets of the config file and of the project file,
+// which take precedence, keyed by name.
+func Presets() (map[string]S.Preset, error) {
+	presets := map[string]S.Preset{}
+	for name, flags := range getConfigFileMap("presets") {
+		preset := S.Preset{Name: name, Source: PresetSourceConfig, Flags: presetFlags(flags)}
+		if err := validatePresetFlags(preset.Flags); err != nil {
+			return nil, fmt.Errorf("invalid preset %s in the config file: %w", name, err)
+		}
+		presets[name] = preset
+	}
+	for name, flags := range projectTable("presets") {
+		if err := ValidatePresetName(name); err != nil {
+			return nil, fmt.Errorf("invalid preset in %s: %w", ProjectFile(), err)
+		}
+		preset := S.Preset{Name: name, Source: PresetSourceProject, Flags: presetFlags(flags)}
+		if err := validatePresetFlags(preset.Flags); err != nil {
+			return nil, fmt.Errorf("invalid preset %s in %s: %w", name, ProjectFile(), err)
+		}
+		presets[name] = preset
+	}
+	return presets, nil
+}
+
+// GetPreset returns the preset called name.
+func GetPreset(name string) (S.Preset, error) {
+	presets, err := Presets()
+	if err != nil {
+		return S.Preset{}, err
+	}
+	preset, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return S.Preset{}, fmt.Errorf("the preset %s doesn't exist", name)
+	}
+	return preset, nil
+}
+
+// presetFlags turns the table of a preset into flag values.
+func presetFlags(table interface{}) map[string]string {
+	flags := map[string]string{}
+	fields, _ := table.(map[string]interface{})
+	for name, value := range fields {
+		flags[name] = flagValue(value)
+	}
+	return flags
+}
+
+// validatePresetFlags checks the values of a preset against the transcribe
+// settings and normalizes them. Unknown flags are left to the transcribe
+// command, which reports them when the preset is used.
+func validatePresetFlags(flags map[string]string) error {
+	for name, value := range flags {
+		setting, err := FindSetting("transcribe." + name)
+		if err != nil {
+			continue
+		}
+		if flags[name], err = ValidateSetting(setting, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavePreset stores flags as the preset called name in the config file,
+// replacing any preset with the same name.
+func SavePreset(name string, flags map[string]string) error {
+	if err := ValidatePresetName(name); err != nil {
+		return err
+	}
+	if len(flags) == 0 {
+		return fmt.Errorf("the preset %s has no flags", name)
+	}
+	if err := validatePresetFlags(flags); err != nil {
+		return err
+	}
+	CreateConfigFile()
+	UnsetConfigFileValue("presets." + name)
+	for flag, value := range flags {
+		SetConfigFileValue("presets."+name+"."+flag, value)
+	}
+	return nil
+}
+
+// DeletePreset removes a preset from the config file. Presets of the project
+// file have to be removed from it by hand.
+func DeletePreset(name string) error {
+	if UnsetConfigFileValue("presets." + strings.ToLower(name)) {
+		return nil
+	}
+	if preset, err := GetPreset(name); err == nil && preset.Source == PresetSourceProject {
+		return fmt.Errorf("the preset %s is defined in %s, edit it there", name, ProjectFile())
+	}
+	return fmt.Errorf("the preset %s doesn't exist", name)
+}
+
+// PresetFlagNames returns the names of the flags of a preset, sorted.
+func PresetFlagNames(preset S.Preset) []string {
+	names := make([]string, 0, len(preset.Flags))
+	for name := range preset.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PresetArgs returns the flags of a preset as command line arguments, sorted
+// by name.
+func PresetArgs(preset S.Preset) []string {
+	args := []string{}
+	for _, name := range PresetFlagNames(preset) {
+		args = append(args, "--"+name+"="+preset.Flags[name])
+	}
+	return args
+}
+
+// PrintPresets lists the presets with their flags.
+func PrintPresets(w io.Writer, flags S.PresetFlags) {
+	presets, err := Presets()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "Could not read the presets: " + err.Error() + ".",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if flags.Json {
+		list := []S.Preset{}
+		for _, name := range names {
+			list = append(list, presets[name])
+		}
+		printPresetsJSON(w, list)
+		return
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(w, "No presets yet. Create one with \033[1m\033[34massemblyai preset save [name] [flags]\033[0m")
+		return
+	}
+	table := uitable.New()
+	table.Wrap = true
+	table.MaxColWidth = 80
+	table.Separator = " |\t"
+	table.AddRow("| name", "source", "flags")
+	for _, name := range names {
+		table.AddRow("| "+name, presets[name].Source, strings.Join(PresetArgs(presets[name]), " "))
+	}
+	fmt.Fprintln(w, table)
+}
+
+// PrintPreset shows the flags of a preset, one per row.
+func PrintPreset(w io.Writer, preset S.Preset, flags S.PresetFlags) {
+	if flags.Json {
+		printPresetsJSON(w, preset)
+		return
+	}
+	table := uitable.New()
+	table.Wrap = true
+	table.MaxColWidth = 80
+	table.Separator = " |\t"
+	table.AddRow("| flag", "value")
+	for _, arg := range PresetArgs(preset) {
+		name, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		table.AddRow("| "+name, value)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintf(w, "\nFrom the %s file.\n", preset.Source)
+}
+
+func printPresetsJSON(w io.Writer, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintln(w, string(BeutifyJSON(data)))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// DefaultProfile is the profile stored at the top level of the config file,
+// used when no other profile is selected. Named profiles are stored under
+// profiles.[name] with the same keys, e.g. profiles.prod.config.token.
+const DefaultProfile = "default"
+
+// ProfileOverride is the profile selected with the global --profile flag.
+var ProfileOverride string
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ActiveProfile returns the profile selected with --profile, then the
+// ASSEMBLYAI_PROFILE env variable, then with assemblyai config profiles use.
+func ActiveProfile() string {
+	if ProfileOverride != "" {
+		return ProfileOverride
+	}
+	if profile := os.Getenv("ASSEMBLYAI_PROFILE"); profile != "" {
+		return profile
+	}
+	if profile := GetConfigFileValue("config.profile"); profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// ValidateProfileName reports names that can't be used as a config key.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("profile names may only contain lowercase letters, digits, - and _, got %q", name)
+	}
+	return nil
+}
+
+func profileKey(profile string, key string) string {
+	if profile == DefaultProfile {
+		return key
+	}
+	return "profiles." + profile + "." + key
+}
+
+// GetProfileValue returns key from the active profile. Named profiles inherit
+// the settings of the default profile they don't set, except its token.
+func GetProfileValue(key string) string {
+	profile := ActiveProfile()
+	if profile == DefaultProfile {
+		return GetConfigFileValue(key)
+	}
+	value := GetConfigFileValue(profileKey(profile, key))
+	if value == "" && key != "config.token" {
+		value = GetConfigFileValue(key)
+	}
+	return value
+}
+
+// SetProfileValue stores key in profile.
+func SetProfileValue(profile string, key string, value string) {
+	SetConfigFileValue(profileKey(profile, key), value)
+}
+
+// ProfileExists reports whether profile was configured. The default profile
+// always exists.
+func ProfileExists(profile string) bool {
+	return profile == DefaultProfile || len(getConfigFileMap("profiles."+profile)) > 0
+}
+
+// ListProfiles returns the default profile followed by the named ones.
+func ListProfiles() []string {
+	names := []string{}
+	for name := range getConfigFileMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// TranscribeDefaults returns the transcription flags set in the active
+// profile and then in the project file, keyed by flag name.
+func TranscribeDefaults() map[string]string {
+	defaults := map[string]string{}
+	keys := []string{"transcribe"}
+	if profile := ActiveProfile(); profile != DefaultProfile {
+		keys = append(keys, profileKey(profile, "transcribe"))
+	}
+	for _, key := range keys {
+		for name, value := range getConfigFileMap(key) {
+			defaults[name] = fmt.Sprint(value)
+		}
+	}
+	for name, value := range projectTable("transcribe") {
+		defaults[name] = value.(string)
+	}
+	return defaults
+}
+
+// UseProfile makes profile the one used when none is selected.
+func UseProfile(profile string) error {
+	if !ProfileExists(profile) {
+		return fmt.Errorf("the profile %s doesn't exist", profile)
+	}
+	if profile == DefaultProfile {
+		UnsetConfigFileValue("config.profile")
+		return nil
+	}
+	SetConfigFileValue("config.profile", profile)
+	return nil
+}
+
+// RemoveProfile deletes a named profile, and stops using it if it was
+// selected with UseProfile.
+func RemoveProfile(profile string) error {
+	if profile == DefaultProfile {
+		return errors.New("the default profile can't be removed")
+	}
+	if !ProfileExists(profile) {
+		return fmt.Errorf("the profile %s doesn't exist", profile)
+	}
+	if storage := GetConfigFileValue(profileKey(profile, "config.token_storage")); storage != "" {
+		if err := deleteStoredToken(profile, storage); err != nil {
+			return fmt.Errorf("couldn't remove its token from the %s: %w", storage, err)
+		}
+	}
+	UnsetConfigFileValue("profiles." + profile)
+	if GetConfigFileValue("config.profile") == profile {
+		UnsetConfigFileValue("config.profile")
+	}
+	return nil
+}
+
+// PrintProfiles lists the profiles, with their base URL and where their token
+// is stored. The active profile is marked with *.
+func PrintProfiles(w io.Writer) {
+	active := ActiveProfile()
+	table := uitable.New()
+	table.Wrap = true
+	table.Separator = " |\t"
+	table.AddRow("| profile", "active", "base url", "token")
+	for _, profile := range ListProfiles() {
+		marker := ""
+		if profile == active {
+			marker = "*"
+		}
+		baseURL := GetConfigFileValue(profileKey(profile, "api.base_url"))
+		if baseURL == "" {
+			baseURL = GetConfigFileValue("api.base_url")
+		}
+		if baseURL == "" {
+			baseURL = C.DefaultBaseURL
+		}
+		token := GetConfigFileValue(profileKey(profile, "config.token_storage"))
+		if token == "" && GetConfigFileValue(profileKey(profile, "config.token")) != "" {
+			token = TokenStoragePlaintext
+		}
+		if token == "" {
+			token = "missing"
+		}
+		table.AddRow("| "+profile, marker, baseURL, token)
+	}
+	fmt.Fprintln(w, table)
+}
+
+// CheckActiveProfile exits with an error when the selected profile has an
+// invalid name or doesn't exist.
+func CheckActiveProfile() {
+	profile := ActiveProfile()
+	err := ValidateProfileName(profile)
+	if err == nil && !ProfileExists(profile) {
+		err = fmt.Errorf("the profile %s doesn't exist", profile)
+	}
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Invalid profile: %s. Create it with \033[1m\033[34massemblyai config --profile %s [token]\033[0m", err, profile),
+		}
+		PrintError(printErrorProps)
+	}
+}
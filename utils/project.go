@@ -0,0 +1,210 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames are the project files looked for, in this order, in the
+// current directory and then in each of its parents.
+var ProjectFileNames = []string{".assemblyai.toml", ".assemblyai.yaml", ".assemblyai.yml"}
+
+// projectSections are the tables project files may set. Connection settings
+// and tokens are left out, so a cloned repository can't send your token to
+// another host.
+var projectSections = []string{"transcribe", "output", "presets"}
+
+// projectFlags are the only transcribe flags project files can set, in their
+// transcribe table or in presets: the parameters of the transcription. Flags
+// that write files, read manifests or send transcripts to a webhook are left
+// out, so a cloned repository can't overwrite your files or collect your
+// transcripts.
+var projectFlags = []string{
+	"auto_chapters", "auto_highlights", "boost_param", "content_moderation", "custom_spelling",
+	"disfluencies", "dual_channel", "entity_detection", "format_text", "language_code",
+	"language_detection", "punctuate", "redact_pii", "redact_pii_audio", "redact_pii_audio_quality",
+	"redact_pii_policies", "redact_pii_sub", "sentiment_analysis", "speaker_labels", "summarization",
+	"summary_model", "summary_type", "topic_detection", "word_boost",
+}
+
+// projectConfig is the project file found for the current directory.
+type projectConfig struct {
+	path     string
+	settings map[string]interface{}
+}
+
+var project *projectConfig
+
+// FindProjectFile returns the first project file found in dir or one of its
+// parents, or "" when there's none.
+func FindProjectFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, name := range ProjectFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ProjectFile returns the path of the project file in use, or "".
+func ProjectFile() string {
+	return loadProject().path
+}
+
+// loadProject reads the project file of the current directory once. Invalid
+// files are reported as errors, and settings project files can't hold as
+// warnings.
+func loadProject() *projectConfig {
+	if project != nil {
+		return project
+	}
+	project = &projectConfig{settings: map[string]interface{}{}}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return project
+	}
+	path := FindProjectFile(cwd)
+	if path == "" {
+		return project
+	}
+	settings, err := readProjectFile(path)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Invalid project file %s: %s.", path, err),
+		}
+		PrintError(printErrorProps)
+		return project
+	}
+	project.path = path
+	project.settings = settings
+	return project
+}
+
+func readProjectFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if filepath.Ext(path) == ".toml" {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]interface{}{}
+	for section, value := range raw {
+		table, ok := value.(map[string]interface{})
+		if !Contains(projectSections, section) || !ok {
+			fmt.Fprintf(os.Stderr, "Ignoring %s in %s: project files can only hold the %s tables.\n", section, path, strings.Join(projectSections, ", "))
+			continue
+		}
+		if section == "presets" {
+			for name, preset := range table {
+				flags, _ := preset.(map[string]interface{})
+				for flag := range flags {
+					// Unknown flags are kept, so using the preset reports them.
+					if _, err := FindSetting("transcribe." + flag); err == nil && !Contains(projectFlags, flag) {
+						fmt.Fprintf(os.Stderr, "Ignoring %s of the %s preset in %s: project files can only set transcription parameters.\n", flag, name, path)
+						delete(flags, flag)
+					}
+				}
+			}
+			settings[section] = table
+			continue
+		}
+		values := map[string]interface{}{}
+		for name, value := range table {
+			key := section + "." + name
+			if section == "transcribe" && !Contains(projectFlags, name) {
+				fmt.Fprintf(os.Stderr, "Ignoring %s in %s: project files can only set transcription parameters.\n", key, path)
+				continue
+			}
+			setting, err := FindSetting(key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Ignoring %s in %s: %s.\n", key, path, err)
+				continue
+			}
+			text := flagValue(value)
+			if key == "transcribe.custom_spelling" {
+				text = projectPath(path, text)
+			}
+			if text, err = ValidateSetting(setting, text); err != nil {
+				return nil, err
+			}
+			values[name] = text
+		}
+		settings[section] = values
+	}
+	return settings, nil
+}
+
+// projectPath resolves a custom spelling file against the folder of the
+// project file, leaving inline JSON untouched.
+func projectPath(projectFile string, value string) string {
+	if value == "" || strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") || filepath.IsAbs(value) {
+		return value
+	}
+	return filepath.Join(filepath.Dir(projectFile), value)
+}
+
+// flagValue turns a value of a config or project file into the text a flag
+// expects: lists of words are joined with commas, and tables as well as lists
+// of tables, such as custom spellings, become JSON.
+func flagValue(value interface{}) string {
+	switch value := value.(type) {
+	case []interface{}:
+		items := []string{}
+		for _, item := range value {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(value)
+				return string(data)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// projectTable returns the table of the project file under section.
+func projectTable(section string) map[string]interface{} {
+	table, _ := loadProject().settings[section].(map[string]interface{})
+	return table
+}
+
+// projectValue returns key from the project file.
+func projectValue(key string) (string, bool) {
+	section, name, _ := strings.Cut(key, ".")
+	value, ok := projectTable(section)[name].(string)
+	return value, ok
+}
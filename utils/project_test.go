@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	os.MkdirAll(nested, 0755)
+	if got := FindProjectFile(nested); got != "" {
+		t.Errorf("Expected no project file, got %s.", got)
+	}
+
+	yaml := filepath.Join(root, ".assemblyai.yaml")
+	os.WriteFile(yaml, []byte("transcribe: {}\n"), 0644)
+	if got := FindProjectFile(nested); got != yaml {
+		t.Errorf("Expected %s from a parent folder, got %s.", yaml, got)
+	}
+
+	toml := filepath.Join(root, "a", ".assemblyai.toml")
+	os.WriteFile(toml, []byte("[transcribe]\n"), 0644)
+	if got := FindProjectFile(nested); got != toml {
+		t.Errorf("Expected the closest project file %s, got %s.", toml, got)
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{true, "true"},
+		{int64(3), "3"},
+		{[]interface{}{"person_name", "phone_number"}, "person_name,phone_number"},
+		{[]interface{}{map[string]interface{}{"from": []interface{}{"ai"}, "to": "AI"}}, `[{"from":["ai"],"to":"AI"}]`},
+	}
+	for _, test := range tests {
+		if got := flagValue(test.value); got != test.want {
+			t.Errorf("flagValue(%v) = %s, want %s.", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// DownloadRedactedAudio waits for the PII-redacted audio of a transcript and
+// downloads it, by default to [id].[extension] in the current directory.
+func DownloadRedactedAudio(client *C.Client, id string, flags S.RedactedAudioFlags) {
+	s := CallSpinner(" Waiting for the redacted audio...")
+	transcript, err := client.WaitForTranscript(context.Background(), id, pollInterval)
+	if err == nil && transcript.Error != nil {
+		err = errors.New(*transcript.Error)
+	}
+	if err != nil {
+		s.Stop()
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't fetch the transcription: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	if transcript.RedactPiiAudio == nil || !*transcript.RedactPiiAudio {
+		s.Stop()
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New("No redacted audio"),
+			Message: "This transcription has no redacted audio. Transcribe the file with --redact_pii and --redact_pii_audio to create it.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	redactedAudio, err := client.WaitForRedactedAudio(context.Background(), id, pollInterval)
+	s.Stop()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't fetch the redacted audio: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	if flags.UrlOnly {
+		fmt.Println(redactedAudio.RedactedAudioURL)
+		return
+	}
+	output := flags.Output
+	if output == "" {
+		output = id + redactedAudioExtension(redactedAudio.RedactedAudioURL)
+	}
+	if err := downloadFile(client, redactedAudio.RedactedAudioURL, output); err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't download the redacted audio: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Redacted audio saved to %s\n", output)
+}
+
+// redactedAudioExtension returns the extension of the file a redacted audio
+// URL points to, which depends on the quality it was requested in.
+func redactedAudioExtension(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if extension := path.Ext(parsed.Path); extension != "" {
+			return extension
+		}
+	}
+	return ".mp3"
+}
+
+// downloadFile downloads url to output with a progress bar. The file is
+// written next to output first, so a failed download doesn't leave a partial
+// file behind.
+func downloadFile(client *C.Client, url string, output string) error {
+	body, size, err := client.Download(context.Background(), url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	bar := pb.New64(size)
+	if size < 0 {
+		bar = pb.New(0)
+	}
+	bar.Output = os.Stderr
+	bar.SetUnits(pb.U_BYTES_DEC)
+	bar.Prefix("Downloading redacted audio: ")
+	bar.ShowBar = false
+	bar.ShowTimeLeft = false
+	bar.Start()
+	_, err = io.Copy(file, bar.NewProxyReader(body))
+	bar.Finish()
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), output)
+}
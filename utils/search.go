@@ -0,0 +1,513 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+	"golang.org/x/term"
+)
+
+// SearchIndex is an inverted index of the words of transcripts.
+type SearchIndex struct {
+	docs     []searchDoc
+	postings map[string][]posting
+}
+
+type searchDoc struct {
+	id     string
+	source string
+	words  []S.SentimentAnalysisResult
+	tokens []string
+}
+
+type posting struct {
+	doc int
+	pos int
+}
+
+// span is a match of length words starting at the word pos of a document.
+type span struct {
+	pos    int
+	length int
+}
+
+// matches maps documents to the spans matched in them. A document can match
+// without spans, e.g. a NOT query.
+type matches map[int][]span
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{postings: map[string][]posting{}}
+}
+
+// Add indexes the words of a transcript.
+func (ix *SearchIndex) Add(id string, source string, words []S.SentimentAnalysisResult) {
+	doc := searchDoc{id: id, source: source, words: words, tokens: make([]string, len(words))}
+	index := len(ix.docs)
+	for pos, word := range words {
+		token := normalizeToken(word.Text)
+		doc.tokens[pos] = token
+		if token != "" {
+			ix.postings[token] = append(ix.postings[token], posting{doc: index, pos: pos})
+		}
+	}
+	ix.docs = append(ix.docs, doc)
+}
+
+// normalizeToken lowercases text and trims the punctuation around it, so
+// "Competitor," matches "competitor".
+func normalizeToken(text string) string {
+	return strings.ToLower(strings.TrimFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// Search returns the hits of query, in the order transcripts were added and
+// then by position. Each hit has up to context words around it.
+func (ix *SearchIndex) Search(query string, context int) ([]S.SearchHit, error) {
+	node, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if !node.positive() {
+		return nil, errors.New("the query needs at least one term that isn't negated")
+	}
+	result := node.eval(ix)
+
+	docs := make([]int, 0, len(result))
+	for doc := range result {
+		docs = append(docs, doc)
+	}
+	sort.Ints(docs)
+
+	hits := []S.SearchHit{}
+	for _, doc := range docs {
+		spans := result[doc]
+		sort.Slice(spans, func(i, j int) bool { return spans[i].pos < spans[j].pos })
+		last := -1
+		for _, match := range spans {
+			if match.pos <= last {
+				continue
+			}
+			last = match.pos + match.length - 1
+			hits = append(hits, ix.hit(doc, match, context))
+		}
+	}
+	return hits, nil
+}
+
+func (ix *SearchIndex) hit(doc int, match span, context int) S.SearchHit {
+	d := ix.docs[doc]
+	text := func(from int, to int) string {
+		if from < 0 {
+			from = 0
+		}
+		if to > len(d.words) {
+			to = len(d.words)
+		}
+		parts := []string{}
+		for _, word := range d.words[from:to] {
+			parts = append(parts, word.Text)
+		}
+		return strings.Join(parts, " ")
+	}
+	first := d.words[match.pos]
+	last := d.words[match.pos+match.length-1]
+	hit := S.SearchHit{
+		ID:      d.id,
+		Source:  d.source,
+		Speaker: first.Speaker,
+		Text:    text(match.pos, match.pos+match.length),
+	}
+	if first.Start != nil {
+		hit.Start = *first.Start
+	}
+	if last.End != nil {
+		hit.End = *last.End
+	}
+	if context > 0 {
+		hit.Before = text(match.pos-context, match.pos)
+		hit.After = text(match.pos+match.length, match.pos+match.length+context)
+	}
+	return hit
+}
+
+// queryNode is a node of a parsed search query.
+type queryNode interface {
+	eval(ix *SearchIndex) matches
+	// positive reports whether the node can match without only excluding
+	// documents.
+	positive() bool
+}
+
+// phraseNode matches consecutive words. A single word ending with * matches
+// every word starting with it.
+type phraseNode struct {
+	tokens []string
+}
+
+func (n phraseNode) eval(ix *SearchIndex) matches {
+	result := matches{}
+	first := n.tokens[0]
+	candidates := ix.postings[first]
+	if len(n.tokens) == 1 && strings.HasSuffix(first, "*") {
+		candidates = nil
+		prefix := strings.TrimSuffix(first, "*")
+		for token, postings := range ix.postings {
+			if strings.HasPrefix(token, prefix) {
+				candidates = append(candidates, postings...)
+			}
+		}
+	}
+	for _, p := range candidates {
+		tokens := ix.docs[p.doc].tokens
+		if p.pos+len(n.tokens) > len(tokens) {
+			continue
+		}
+		found := true
+		for i := 1; i < len(n.tokens); i++ {
+			if tokens[p.pos+i] != n.tokens[i] {
+				found = false
+				break
+			}
+		}
+		if found {
+			result[p.doc] = append(result[p.doc], span{pos: p.pos, length: len(n.tokens)})
+		}
+	}
+	return result
+}
+
+func (n phraseNode) positive() bool { return true }
+
+type andNode struct {
+	children []queryNode
+}
+
+func (n andNode) eval(ix *SearchIndex) matches {
+	result := n.children[0].eval(ix)
+	for _, child := range n.children[1:] {
+		other := child.eval(ix)
+		for doc, spans := range result {
+			otherSpans, ok := other[doc]
+			if !ok {
+				delete(result, doc)
+				continue
+			}
+			result[doc] = append(spans, otherSpans...)
+		}
+	}
+	return result
+}
+
+func (n andNode) positive() bool {
+	for _, child := range n.children {
+		if child.positive() {
+			return true
+		}
+	}
+	return false
+}
+
+type orNode struct {
+	children []queryNode
+}
+
+func (n orNode) eval(ix *SearchIndex) matches {
+	result := matches{}
+	for _, child := range n.children {
+		for doc, spans := range child.eval(ix) {
+			result[doc] = append(result[doc], spans...)
+		}
+	}
+	return result
+}
+
+func (n orNode) positive() bool {
+	for _, child := range n.children {
+		if !child.positive() {
+			return false
+		}
+	}
+	return true
+}
+
+type notNode struct {
+	child queryNode
+}
+
+func (n notNode) eval(ix *SearchIndex) matches {
+	excluded := n.child.eval(ix)
+	result := matches{}
+	for doc := range ix.docs {
+		if _, ok := excluded[doc]; !ok {
+			result[doc] = nil
+		}
+	}
+	return result
+}
+
+func (n notNode) positive() bool { return false }
+
+// parseQuery parses a search query. Words next to each other must all match,
+// "quoted phrases" match consecutive words, OR matches either side, NOT or a
+// leading - excludes transcripts, and parentheses group. AND can be written
+// out too. A word ending with * matches every word starting with it.
+//
+//	query := or
+//	or    := and (OR and)*
+//	and   := not ([AND] not)*
+//	not   := (NOT | -) not | term | "phrase" | ( query )
+func parseQuery(query string) (queryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("the query is empty")
+	}
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	return node, nil
+}
+
+type queryToken struct {
+	text   string
+	phrase bool
+}
+
+func (t queryToken) is(keyword string) bool {
+	return !t.phrase && t.text == keyword
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{text: string(r)})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("a phrase is missing its closing quote")
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i+1 : end]), phrase: true})
+			i = end + 1
+		case r == '-' && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, queryToken{text: "NOT"})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return queryToken{}, false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []queryNode{node}
+	for {
+		token, ok := p.peek()
+		if !ok || !token.is("OR") {
+			break
+		}
+		p.pos++
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return orNode{children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	node, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	children := []queryNode{node}
+	for {
+		token, ok := p.peek()
+		if !ok || token.is("OR") || token.is(")") {
+			break
+		}
+		if token.is("AND") {
+			p.pos++
+		}
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return andNode{children: children}, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, errors.New("the query ends too early")
+	}
+	p.pos++
+	switch {
+	case token.is("NOT"):
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child: child}, nil
+	case token.is("("):
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || !closing.is(")") {
+			return nil, errors.New("a parenthesis isn't closed")
+		}
+		p.pos++
+		return node, nil
+	case token.is(")"), token.is("AND"), token.is("OR"):
+		return nil, fmt.Errorf("unexpected %s", token.text)
+	}
+
+	words := []string{}
+	for _, word := range strings.Fields(token.text) {
+		normalized := normalizeToken(word)
+		if strings.HasSuffix(word, "*") && !token.phrase && normalized != "" {
+			normalized += "*"
+		}
+		if normalized != "" {
+			words = append(words, normalized)
+		}
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%q has no words to search for", token.text)
+	}
+	return phraseNode{tokens: words}, nil
+}
+
+// SearchTranscripts searches the completed transcripts of the local history.
+func SearchTranscripts(query string, flags S.SearchFlags) {
+	ix := NewSearchIndex()
+	err := cachedTranscripts(func(entry S.HistoryEntry, transcript S.TranscriptResponse) {
+		ix.Add(entry.ID, entry.Source, transcript.Words)
+	})
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't read the local history.",
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	hits, err := ix.Search(query, flags.Context)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("Invalid search query: %v.", err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	if flags.IdsOnly {
+		ids := []string{}
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		for _, id := range unique(ids) {
+			fmt.Println(id)
+		}
+		return
+	}
+	if flags.Limit > 0 && len(hits) > flags.Limit {
+		hits = hits[:flags.Limit]
+	}
+	if flags.Json {
+		data, _ := json.Marshal(hits)
+		fmt.Println(string(BeutifyJSON(data)))
+		return
+	}
+	if len(hits) == 0 {
+		fmt.Fprintf(os.Stderr, "No matches in %d transcript(s). Only transcripts in the local history are searched.\n", len(ix.docs))
+		return
+	}
+	searchPrintFormatted(os.Stdout, hits, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+func searchPrintFormatted(w io.Writer, hits []S.SearchHit, highlight bool) {
+	table := uitable.New()
+	table.Wrap = true
+	table.Separator = " |\t"
+	table.AddRow("| id", "time", "speaker", "text")
+	for _, hit := range hits {
+		text := hit.Text
+		if highlight {
+			text = "\033[1m" + text + "\033[0m"
+		}
+		if hit.Before != "" {
+			text = "..." + hit.Before + " " + text
+		}
+		if hit.After != "" {
+			text += " " + hit.After + "..."
+		}
+		speaker := hit.Speaker
+		if speaker == "" {
+			speaker = "-"
+		}
+		table.AddRow("| "+hit.ID, TransformMsToTimestamp(hit.Start, false), speaker, text)
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+// searchWords returns the words of text, 500ms each, spoken by speaker.
+func searchWords(speaker string, text string) []S.SentimentAnalysisResult {
+	words := []S.SentimentAnalysisResult{}
+	for i, word := range strings.Fields(text) {
+		start := int64(i * 500)
+		end := start + 400
+		words = append(words, S.SentimentAnalysisResult{Text: word, Start: &start, End: &end, Speaker: speaker})
+	}
+	return words
+}
+
+func TestSearch(t *testing.T) {
+	ix := NewSearchIndex()
+	ix.Add("tr_sales", "sales.mp3", searchWords("A", "We lost the deal to Acme, their pricing page was better."))
+	ix.Add("tr_support", "support.mp3", searchWords("B", "Acme support called about the pricing of the new plan."))
+	ix.Add("tr_internal", "", searchWords("", "Internal sync about hiring and planning."))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"acme", []string{"tr_sales:Acme,", "tr_support:Acme"}},
+		{"ACME pricing", []string{"tr_sales:Acme,", "tr_sales:pricing", "tr_support:Acme", "tr_support:pricing"}},
+		{"acme AND page", []string{"tr_sales:Acme,", "tr_sales:page"}},
+		{`"pricing page"`, []string{"tr_sales:pricing page"}},
+		{`"pricing of the"`, []string{"tr_support:pricing of the"}},
+		{"hiring OR deal", []string{"tr_sales:deal", "tr_internal:hiring"}},
+		{"about -acme", []string{"tr_internal:about"}},
+		{"about NOT (support OR deal)", []string{"tr_internal:about"}},
+		{"plan*", []string{"tr_support:plan.", "tr_internal:planning."}},
+		{"(deal OR support) pricing", []string{"tr_sales:deal", "tr_sales:pricing", "tr_support:support", "tr_support:pricing"}},
+		{"competitor", []string{}},
+	}
+	for _, test := range tests {
+		hits, err := ix.Search(test.query, 0)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		got := []string{}
+		for _, hit := range hits {
+			got = append(got, hit.ID+":"+hit.Text)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.query, test.want, got)
+		}
+	}
+}
+
+func TestSearchHit(t *testing.T) {
+	ix := NewSearchIndex()
+	ix.Add("tr_sales", "sales.mp3", searchWords("A", "We lost the deal to Acme, their pricing page was better."))
+
+	hits, err := ix.Search(`"pricing page"`, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []S.SearchHit{{
+		ID:      "tr_sales",
+		Source:  "sales.mp3",
+		Start:   3500,
+		End:     4400,
+		Speaker: "A",
+		Text:    "pricing page",
+		Before:  "Acme, their",
+		After:   "was better.",
+	}}
+	if !reflect.DeepEqual(hits, want) {
+		t.Errorf("Expected %+v, got %+v", want, hits)
+	}
+
+	if hits, _ := ix.Search("we", 3); len(hits) != 1 || hits[0].Before != "" || hits[0].After != "lost the deal" {
+		t.Errorf("Expected the context to stop at the start of the transcript, got %+v", hits)
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	ix := NewSearchIndex()
+	for _, query := range []string{"", "-acme", "NOT acme", `"pricing page`, "(acme", "acme)", "acme OR", "OR acme", "..."} {
+		if _, err := ix.Search(query, 0); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
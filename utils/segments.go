@@ -0,0 +1,82 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// fetchSegments sets the sentences or paragraphs of a completed transcript,
+// so the formatted transcript uses them instead of splitting the text locally.
+func fetchSegments(ctx context.Context, client *C.Client, transcript *S.TranscriptResponse, segmentation string) error {
+	switch segmentation {
+	case "sentences":
+		sentences, err := client.GetSentences(ctx, *transcript.ID)
+		if err != nil {
+			return err
+		}
+		transcript.Segments = &sentences.Sentences
+	case "paragraphs":
+		paragraphs, err := client.GetParagraphs(ctx, *transcript.ID)
+		if err != nil {
+			return err
+		}
+		transcript.Segments = &paragraphs.Paragraphs
+	default:
+		return fmt.Errorf("unknown segmentation %q", segmentation)
+	}
+	return nil
+}
+
+func printSegmentsError(err error, segmentation string) {
+	printErrorProps := S.PrintErrorProps{
+		Error:   err,
+		Message: fmt.Sprintf("We couldn't fetch the %s of the transcription: %s", segmentation, apiErrorMessage(err)),
+	}
+	PrintError(printErrorProps)
+}
+
+// segmentsPrintFormatted prints one row per segment as [start - end], then
+// the speaker or channel when there is one, then the text.
+func segmentsPrintFormatted(w io.Writer, width int, segments []S.SentimentAnalysisResult, dualChannel bool) {
+	table := uitable.New()
+	table.Wrap = true
+	table.MaxColWidth = uint(width - 18)
+	for _, segment := range segments {
+		if segment.Speaker != "" || (dualChannel && segment.Channel != "") {
+			table.MaxColWidth = uint(width - 30)
+			break
+		}
+	}
+	for _, segment := range segments {
+		times := []string{}
+		if segment.Start != nil {
+			times = append(times, TransformMsToTimestamp(*segment.Start, false))
+		}
+		if segment.End != nil {
+			times = append(times, TransformMsToTimestamp(*segment.End, false))
+		}
+		interval := ""
+		if len(times) > 0 {
+			interval = "[" + strings.Join(times, " - ") + "]"
+		}
+		switch {
+		case dualChannel && segment.Channel != "":
+			table.AddRow(interval, fmt.Sprintf("Channel %s:", segment.Channel), segment.Text)
+		case segment.Speaker != "":
+			table.AddRow(interval, fmt.Sprintf("Speaker %s:", segment.Speaker), segment.Text)
+		default:
+			table.AddRow(interval, segment.Text)
+		}
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// The types of settings, which decide the values they accept.
+const (
+	SettingBool     = "bool"
+	SettingInt      = "int"
+	SettingDuration = "duration"
+	SettingURL      = "url"
+	SettingEnum     = "enum"
+	SettingString   = "string"
+)
+
+// DefaultPollInterval is how long to wait between two status checks of a
+// transcript when api.poll_interval isn't set.
+const DefaultPollInterval = 3 * time.Second
+
+// registeredSettings are the settings added with RegisterSetting, e.g. the
+// transcription defaults of the transcribe command.
+var registeredSettings = []S.ConfigSetting{}
+
+// RegisterSetting adds a setting to the schema.
+func RegisterSetting(setting S.ConfigSetting) {
+	registeredSettings = append(registeredSettings, setting)
+}
+
+// ConfigSettings returns the schema of the settings, sorted by key.
+func ConfigSettings() []S.ConfigSetting {
+	settings := []S.ConfigSetting{
+		{Key: "features.telemetry", Type: SettingBool, Default: "true", Global: true, Description: "Send anonymous usage data and error reports to AssemblyAI."},
+		{Key: "api.base_url", Type: SettingURL, Default: C.DefaultBaseURL, Env: "ASSEMBLYAI_BASE_URL", Description: "The AssemblyAI API host to send requests to."},
+		{Key: "api.proxy", Type: SettingURL, Env: "ASSEMBLYAI_PROXY", Description: "URL of the HTTP(S) proxy to use."},
+		{Key: "api.ca_bundle", Type: SettingString, Env: "ASSEMBLYAI_CA_BUNDLE", Description: "Path to a PEM file with additional certificate authorities to trust."},
+		{Key: "api.client_cert", Type: SettingString, Env: "ASSEMBLYAI_CLIENT_CERT", Description: "Path to a PEM client certificate for mutual TLS."},
+		{Key: "api.client_key", Type: SettingString, Env: "ASSEMBLYAI_CLIENT_KEY", Description: "Path to the PEM key of the client certificate."},
+		{Key: "api.connect_timeout", Type: SettingDuration, Default: DefaultConnectTimeout.String(), Env: "ASSEMBLYAI_CONNECT_TIMEOUT", Description: "The maximum time to establish a connection."},
+		{Key: "api.read_timeout", Type: SettingDuration, Default: DefaultReadTimeout.String(), Env: "ASSEMBLYAI_READ_TIMEOUT", Description: "The maximum time to wait for a response once a request is sent."},
+		{Key: "api.max_retries", Type: SettingInt, Default: strconv.Itoa(C.DefaultRetryPolicy.MaxRetries), Env: "ASSEMBLYAI_MAX_RETRIES", Description: "How many times failed API requests are retried."},
+		{Key: "api.retry_timeout", Type: SettingDuration, Default: C.DefaultRetryPolicy.Timeout.String(), Env: "ASSEMBLYAI_RETRY_TIMEOUT", Description: "The maximum time spent retrying a single API request."},
+		{Key: "api.poll_interval", Type: SettingDuration, Default: DefaultPollInterval.String(), Env: "ASSEMBLYAI_POLL_INTERVAL", Description: "How long to wait between two status checks of a transcription."},
+		{Key: "output.format", Type: SettingEnum, Values: FormatNames(), Default: "text", Description: "The output format of transcriptions when --format isn't given."},
+	}
+	settings = append(settings, registeredSettings...)
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// FindSetting returns the schema of key, or an error naming the settings it
+// could have meant.
+func FindSetting(key string) (S.ConfigSetting, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	suggestions := []string{}
+	for _, setting := range ConfigSettings() {
+		if setting.Key == key {
+			return setting, nil
+		}
+		if key != "" && (strings.HasSuffix(setting.Key, "."+key) || strings.Contains(setting.Key, key)) {
+			suggestions = append(suggestions, setting.Key)
+		}
+	}
+	if len(suggestions) > 0 && len(suggestions) <= 3 {
+		return S.ConfigSetting{}, fmt.Errorf("unknown setting %q, maybe %s", key, strings.Join(suggestions, " or "))
+	}
+	return S.ConfigSetting{}, fmt.Errorf("unknown setting %q, run assemblyai config list to see the settings", key)
+}
+
+// ValidateSetting checks value against the type of setting and returns it
+// in its canonical form, e.g. "true" for "1".
+func ValidateSetting(setting S.ConfigSetting, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch setting.Type {
+	case SettingBool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s expects true or false, got %q", setting.Key, value)
+		}
+		return strconv.FormatBool(parsed), nil
+	case SettingInt:
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return "", fmt.Errorf("%s expects a positive whole number, got %q", setting.Key, value)
+		}
+		return strconv.Itoa(parsed), nil
+	case SettingDuration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return "", fmt.Errorf("%s expects a positive duration such as 30s or 2m, got %q", setting.Key, value)
+		}
+		return parsed.String(), nil
+	case SettingURL:
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "", fmt.Errorf("%s expects an http or https URL, got %q", setting.Key, value)
+		}
+		return value, nil
+	case SettingEnum:
+		if !Contains(setting.Values, value) {
+			return "", fmt.Errorf("%s expects one of %s, got %q", setting.Key, strings.Join(setting.Values, ", "), value)
+		}
+		return value, nil
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s can't be empty, use assemblyai config unset to remove it", setting.Key)
+	}
+	return value, nil
+}
+
+// ResolveSetting returns the value of setting for the active profile and
+// where it comes from: a global flag, its env variable, the project file, the
+// profile, the default profile it inherits from, or the default value.
+func ResolveSetting(setting S.ConfigSetting) S.ConfigValue {
+	value := S.ConfigValue{ConfigSetting: setting, Value: setting.Default, Source: "default"}
+	profile := ActiveProfile()
+	projectSetting, inProject := projectValue(setting.Key)
+	switch {
+	case FlagOverrides[setting.Key] != "":
+		value.Value, value.Source = FlagOverrides[setting.Key], "flag"
+	case setting.Env != "" && os.Getenv(setting.Env) != "":
+		value.Value, value.Source = os.Getenv(setting.Env), setting.Env
+	case inProject:
+		value.Value, value.Source = projectSetting, ProjectFile()
+	case !setting.Global && profile != DefaultProfile && GetConfigFileValue(profileKey(profile, setting.Key)) != "":
+		value.Value, value.Source = GetConfigFileValue(profileKey(profile, setting.Key)), profile+" profile"
+	case GetConfigFileValue(setting.Key) != "":
+		value.Value, value.Source = GetConfigFileValue(setting.Key), "config file"
+	}
+	return value
+}
+
+// SetSetting validates value and stores it in profile, or at the top level
+// of the config file for global settings.
+func SetSetting(profile string, key string, value string) (string, error) {
+	setting, err := FindSetting(key)
+	if err != nil {
+		return "", err
+	}
+	value, err = ValidateSetting(setting, value)
+	if err != nil {
+		return "", err
+	}
+	CreateConfigFile()
+	if setting.Global {
+		SetConfigFileValue(setting.Key, value)
+	} else {
+		SetProfileValue(profile, setting.Key, value)
+	}
+	return value, nil
+}
+
+// UnsetSetting removes key from profile, so it falls back to its default. It
+// reports whether key was set.
+func UnsetSetting(profile string, key string) (bool, error) {
+	setting, err := FindSetting(key)
+	if err != nil {
+		return false, err
+	}
+	if setting.Global {
+		return UnsetConfigFileValue(setting.Key), nil
+	}
+	return UnsetConfigFileValue(profileKey(profile, setting.Key)), nil
+}
+
+// PrintSettings lists every setting with its value for the active profile.
+func PrintSettings(w io.Writer, flags S.ConfigFlags) {
+	values := []S.ConfigValue{}
+	for _, setting := range ConfigSettings() {
+		values = append(values, ResolveSetting(setting))
+	}
+	if flags.Json {
+		data, _ := json.Marshal(values)
+		fmt.Fprintln(w, string(BeutifyJSON(data)))
+		return
+	}
+	printSettingsTable(w, values)
+}
+
+// PrintWhich prints the project file and the profile in use, followed by the
+// effective value of every setting and where it comes from.
+func PrintWhich(w io.Writer, flags S.ConfigFlags) {
+	which := S.ConfigWhich{ProjectFile: ProjectFile(), Profile: ActiveProfile(), Settings: []S.ConfigValue{}}
+	for _, setting := range ConfigSettings() {
+		which.Settings = append(which.Settings, ResolveSetting(setting))
+	}
+	if flags.Json {
+		data, _ := json.Marshal(which)
+		fmt.Fprintln(w, string(BeutifyJSON(data)))
+		return
+	}
+	projectFile := which.ProjectFile
+	if projectFile == "" {
+		projectFile = "none"
+	}
+	fmt.Fprintf(w, "Project file: %s\n", projectFile)
+	fmt.Fprintf(w, "Profile: %s\n\n", which.Profile)
+	printSettingsTable(w, which.Settings)
+}
+
+// PrintSettingSource prints the effective value of a single setting and where
+// it comes from.
+func PrintSettingSource(w io.Writer, setting S.ConfigSetting, flags S.ConfigFlags) {
+	value := ResolveSetting(setting)
+	if flags.Json {
+		data, _ := json.Marshal(value)
+		fmt.Fprintln(w, string(BeutifyJSON(data)))
+		return
+	}
+	fmt.Fprintf(w, "%s = %s (from %s)\n", value.Key, value.Value, value.Source)
+}
+
+func printSettingsTable(w io.Writer, values []S.ConfigValue) {
+	table := uitable.New()
+	table.Wrap = true
+	table.MaxColWidth = 60
+	table.Separator = " |\t"
+	table.AddRow("| key", "value", "source")
+	for _, value := range values {
+		table.AddRow("| "+value.Key, value.Value, value.Source)
+	}
+	fmt.Fprintln(w, table)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+func TestValidateSetting(t *testing.T) {
+	tests := []struct {
+		setting S.ConfigSetting
+		value   string
+		want    string
+		valid   bool
+	}{
+		{S.ConfigSetting{Key: "b", Type: SettingBool}, "1", "true", true},
+		{S.ConfigSetting{Key: "b", Type: SettingBool}, "yes", "", false},
+		{S.ConfigSetting{Key: "i", Type: SettingInt}, " 5 ", "5", true},
+		{S.ConfigSetting{Key: "i", Type: SettingInt}, "-1", "", false},
+		{S.ConfigSetting{Key: "d", Type: SettingDuration}, "90s", "1m30s", true},
+		{S.ConfigSetting{Key: "d", Type: SettingDuration}, "0s", "", false},
+		{S.ConfigSetting{Key: "u", Type: SettingURL}, "http://localhost:8080", "http://localhost:8080", true},
+		{S.ConfigSetting{Key: "u", Type: SettingURL}, "localhost:8080", "", false},
+		{S.ConfigSetting{Key: "e", Type: SettingEnum, Values: []string{"a", "b"}}, "b", "b", true},
+		{S.ConfigSetting{Key: "e", Type: SettingEnum, Values: []string{"a", "b"}}, "c", "", false},
+		{S.ConfigSetting{Key: "s", Type: SettingString}, "", "", false},
+	}
+	for _, test := range tests {
+		got, err := ValidateSetting(test.setting, test.value)
+		if (err == nil) != test.valid || got != test.want {
+			t.Errorf("ValidateSetting(%s, %q) = %q, %v", test.setting.Type, test.value, got, err)
+		}
+	}
+}
+
+func TestFindSetting(t *testing.T) {
+	if setting, err := FindSetting("API.Read_Timeout"); err != nil || setting.Type != SettingDuration {
+		t.Errorf("Expected keys to be case insensitive, got %+v: %v", setting, err)
+	}
+	if _, err := FindSetting("telemetry"); err == nil || err.Error() != `unknown setting "telemetry", maybe features.telemetry` {
+		t.Errorf("Expected a suggestion, got %v", err)
+	}
+}
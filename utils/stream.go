@@ -0,0 +1,373 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"golang.org/x/term"
+)
+
+// DefaultSampleRate is the sample rate assumed for raw PCM audio.
+const DefaultSampleRate = 16000
+
+// MinTokenExpiry and MaxTokenExpiry bound the lifetime of temporary real-time
+// tokens, in seconds.
+const (
+	MinTokenExpiry = 60
+	MaxTokenExpiry = 360000
+)
+
+// streamChunk is how much audio is sent in every message. The API accepts
+// between 100ms and 2s.
+const streamChunk = 200 * time.Millisecond
+
+// minStreamChunk is the shortest audio message the API accepts. The last
+// chunk of a stream is padded with silence up to it.
+const minStreamChunk = 100 * time.Millisecond
+
+// Stream transcribes audio in real time from source: a WAV file, or raw
+// 16-bit mono PCM from a file, a named pipe, or stdin when source is "" or
+// "-". Partial transcripts are updated in place on a terminal, and final ones
+// are printed on their own line.
+func Stream(client *C.Client, source string, flags S.StreamFlags) {
+	input := os.Stdin
+	if source != "" && source != "-" {
+		file, err := os.Open(source)
+		if err != nil {
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "Error opening file",
+			}
+			PrintError(printErrorProps)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+	reader := bufio.NewReader(input)
+	sampleRate, err := streamSampleRate(reader, flags.SampleRate)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: fmt.Sprintf("We can't stream this audio: %s.", err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	session, err := client.StartRealtime(context.Background(), S.RealtimeParams{SampleRate: sampleRate, WordBoost: flags.WordBoost})
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't start the real-time session: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	defer session.Close()
+	fmt.Fprintf(os.Stderr, "Streaming to session %s. Press Ctrl+C to stop.\n", session.ID)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		fmt.Fprintln(os.Stderr, "\nStopping, waiting for the last transcripts. Press Ctrl+C again to quit now.")
+		stop()
+		<-signals
+		os.Exit(ExitInterrupted)
+	}()
+
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- sendAudio(ctx, session, reader, sampleRate, flags.Realtime)
+	}()
+
+	printer := newStreamPrinter(os.Stdout, flags.Json)
+	for {
+		message, err := session.Receive()
+		if message != nil {
+			printer.print(message)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			printer.finish()
+			printErrorProps := S.PrintErrorProps{
+				Error:   err,
+				Message: "The real-time session ended with an error: " + apiErrorMessage(err),
+			}
+			PrintError(printErrorProps)
+			return
+		}
+	}
+	printer.finish()
+	if err := <-sendErr; err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't read the audio: " + err.Error(),
+		}
+		PrintError(printErrorProps)
+	}
+}
+
+// streamSampleRate reads the header of WAV audio and returns its sample rate,
+// or returns the sample rate of raw PCM. The reader is left at the start of
+// the samples.
+func streamSampleRate(reader *bufio.Reader, flagRate int) (int, error) {
+	if flagRate < 0 {
+		return 0, errors.New("the sample rate must be positive")
+	}
+	header, _ := reader.Peek(12)
+	if len(header) < 12 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		if flagRate == 0 {
+			return DefaultSampleRate, nil
+		}
+		return flagRate, nil
+	}
+	wavRate, err := readWavHeader(reader)
+	if err != nil {
+		return 0, err
+	}
+	if flagRate != 0 && flagRate != wavRate {
+		return 0, fmt.Errorf("the file is sampled at %d Hz, not %d Hz", wavRate, flagRate)
+	}
+	return wavRate, nil
+}
+
+// readWavHeader reads the chunks of a WAV file up to its samples, which must
+// be 16-bit mono PCM, and returns their sample rate.
+func readWavHeader(r io.Reader) (int, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return 0, errors.New("the WAV header is incomplete")
+	}
+	sampleRate := 0
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return 0, errors.New("the WAV file has no audio data")
+		}
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			var format struct {
+				AudioFormat   uint16
+				Channels      uint16
+				SampleRate    uint32
+				ByteRate      uint32
+				BlockAlign    uint16
+				BitsPerSample uint16
+			}
+			if chunk.Size < 16 || binary.Read(r, binary.LittleEndian, &format) != nil {
+				return 0, errors.New("the WAV format is incomplete")
+			}
+			if format.AudioFormat != 1 || format.Channels != 1 || format.BitsPerSample != 16 {
+				return 0, fmt.Errorf("only 16-bit mono PCM WAV files can be streamed, this one has %d channel(s) of %d-bit audio in format %d", format.Channels, format.BitsPerSample, format.AudioFormat)
+			}
+			sampleRate = int(format.SampleRate)
+			if _, err := io.CopyN(io.Discard, r, int64(chunk.Size-16+chunk.Size%2)); err != nil {
+				return 0, errors.New("the WAV format is incomplete")
+			}
+		case "data":
+			if sampleRate == 0 {
+				return 0, errors.New("the WAV file has no format before its audio data")
+			}
+			return sampleRate, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(chunk.Size+chunk.Size%2)); err != nil {
+				return 0, errors.New("the WAV file has no audio data")
+			}
+		}
+	}
+}
+
+// sendAudio streams audio in chunks of streamChunk until it ends or ctx is
+// canceled, then terminates the session. When realtime is true, chunks are
+// sent at the speed the audio plays, so files behave like a live source.
+func sendAudio(ctx context.Context, session *C.RealtimeSession, audio io.Reader, sampleRate int, realtime bool) error {
+	bytesPerSecond := sampleRate * 2
+	chunkSize := int(streamChunk.Seconds()*float64(bytesPerSecond)) &^ 1
+	minChunk := int(minStreamChunk.Seconds()*float64(bytesPerSecond)) &^ 1
+	chunks := readChunks(ctx, audio, chunkSize)
+	start := time.Now()
+	var sent int64
+	var readErr error
+	for ctx.Err() == nil {
+		var chunk audioChunk
+		select {
+		case <-ctx.Done():
+			continue
+		case chunk = <-chunks:
+		}
+		if n := len(chunk.data); n > 0 {
+			data := chunk.data
+			if n < minChunk {
+				data = append(data, make([]byte, minChunk-n)...)
+			}
+			if realtime {
+				due := start.Add(time.Duration(sent) * time.Second / time.Duration(bytesPerSecond))
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Until(due)):
+				}
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			if err := session.Send(data); err != nil {
+				return err
+			}
+			sent += int64(len(data))
+		}
+		if chunk.err == io.EOF || chunk.err == io.ErrUnexpectedEOF {
+			break
+		}
+		if chunk.err != nil {
+			readErr = chunk.err
+			break
+		}
+	}
+	if err := session.Terminate(); err != nil && readErr == nil {
+		return err
+	}
+	return readErr
+}
+
+// audioChunk is the result of one read of the audio.
+type audioChunk struct {
+	data []byte
+	err  error
+}
+
+// readChunks reads audio in chunks of size in its own goroutine, so a read
+// blocked on stdin or a pipe doesn't keep the stream from stopping when ctx is
+// canceled. The goroutine is left blocked in that case, until the CLI exits.
+func readChunks(ctx context.Context, audio io.Reader, size int) <-chan audioChunk {
+	chunks := make(chan audioChunk)
+	go func() {
+		for {
+			data := make([]byte, size)
+			n, err := io.ReadFull(audio, data)
+			select {
+			case chunks <- audioChunk{data: data[:n], err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return chunks
+}
+
+// streamPrinter prints the transcripts of a real-time session. On a terminal
+// the current partial transcript is rewritten in place, elsewhere only final
+// transcripts are printed. In JSON mode every transcript is printed as one
+// line of JSON.
+type streamPrinter struct {
+	w       io.Writer
+	json    bool
+	tty     bool
+	width   int
+	partial bool
+}
+
+func newStreamPrinter(w *os.File, json bool) *streamPrinter {
+	printer := &streamPrinter{w: w, json: json}
+	if term.IsTerminal(int(w.Fd())) {
+		printer.tty = true
+		printer.width, _, _ = term.GetSize(int(w.Fd()))
+	}
+	return printer
+}
+
+func (p *streamPrinter) print(message *S.RealtimeMessage) {
+	if message.MessageType != "PartialTranscript" && message.MessageType != "FinalTranscript" {
+		return
+	}
+	if p.json {
+		fmt.Fprintln(p.w, strings.TrimSpace(string(message.Raw)))
+		return
+	}
+	final := message.MessageType == "FinalTranscript"
+	if !p.tty {
+		if final && message.Text != "" {
+			fmt.Fprintln(p.w, message.Text)
+		}
+		return
+	}
+	text := message.Text
+	if !final && p.width > 1 && len([]rune(text)) >= p.width {
+		// Only the last line can be rewritten, so long partial
+		// transcripts show their end.
+		runes := []rune(text)
+		text = "…" + string(runes[len(runes)-p.width+2:])
+	}
+	fmt.Fprint(p.w, "\r\033[K"+text)
+	p.partial = !final && text != ""
+	if final {
+		if text != "" {
+			fmt.Fprintln(p.w)
+		}
+	}
+}
+
+// finish moves past a partial transcript left on the terminal.
+func (p *streamPrinter) finish() {
+	if p.partial {
+		fmt.Fprintln(p.w)
+		p.partial = false
+	}
+}
+
+// ValidateTokenExpiry reports an expiry the API won't accept for temporary
+// real-time tokens.
+func ValidateTokenExpiry(expiresIn int) error {
+	if expiresIn < MinTokenExpiry || expiresIn > MaxTokenExpiry {
+		return fmt.Errorf("the expiry must be between %d and %d seconds, got %d", MinTokenExpiry, MaxTokenExpiry, expiresIn)
+	}
+	return nil
+}
+
+// CreateStreamToken prints a temporary token for real-time sessions.
+func CreateStreamToken(client *C.Client, flags S.StreamTokenFlags) {
+	token, err := client.CreateRealtimeToken(context.Background(), flags.ExpiresIn)
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't create a temporary token: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+	if flags.Json {
+		expiresAt := time.Now().UTC().Add(time.Duration(flags.ExpiresIn) * time.Second).Truncate(time.Second)
+		token.ExpiresAt = &expiresAt
+		data, _ := json.Marshal(token)
+		fmt.Println(string(BeutifyJSON(data)))
+		return
+	}
+	fmt.Println(token.Token)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// wavFile returns a WAV file of samples silent samples, with the chunks in
+// extra between its format and its data.
+func wavFile(sampleRate int, channels int, bits int, samples int, extra ...[]byte) []byte {
+	var buf bytes.Buffer
+	blockAlign := channels * bits / 8
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, uint16(channels)})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(sampleRate), uint32(sampleRate * blockAlign)})
+	binary.Write(&buf, binary.LittleEndian, []uint16{uint16(blockAlign), uint16(bits)})
+	for _, chunk := range extra {
+		buf.Write(chunk)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(samples*blockAlign))
+	buf.Write(make([]byte, samples*blockAlign))
+	return buf.Bytes()
+}
+
+func TestStreamSampleRate(t *testing.T) {
+	list := append([]byte("LIST"), 3, 0, 0, 0, 'a', 'b', 'c', 0)
+	tests := []struct {
+		name     string
+		audio    []byte
+		flagRate int
+		want     int
+		wantErr  bool
+	}{
+		{"raw", make([]byte, 3200), 0, DefaultSampleRate, false},
+		{"raw with rate", make([]byte, 3200), 8000, 8000, false},
+		{"wav", wavFile(44100, 1, 16, 100), 0, 44100, false},
+		{"wav with matching rate", wavFile(8000, 1, 16, 100), 8000, 8000, false},
+		{"wav with odd chunk", wavFile(22050, 1, 16, 100, list), 0, 22050, false},
+		{"wav with other rate", wavFile(8000, 1, 16, 100), 16000, 0, true},
+		{"stereo wav", wavFile(16000, 2, 16, 100), 0, 0, true},
+		{"8-bit wav", wavFile(16000, 1, 8, 100), 0, 0, true},
+		{"truncated wav", wavFile(16000, 1, 16, 100)[:30], 0, 0, true},
+		{"negative rate", make([]byte, 3200), -1, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := bufio.NewReader(bytes.NewReader(test.audio))
+			got, err := streamSampleRate(reader, test.flagRate)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Expected error %v, got %v", test.wantErr, err)
+			}
+			if got != test.want {
+				t.Errorf("Expected %d, got %d", test.want, got)
+			}
+			if err == nil {
+				rest, _ := io.ReadAll(reader)
+				if len(rest) != 200 && len(rest) != 3200 {
+					t.Errorf("Expected the reader to be left at the samples, got %d bytes left", len(rest))
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,10 @@
 package utils
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -13,37 +12,24 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
+	C "github.com/AssemblyAI/assemblyai-cli/client"
 	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	"github.com/gosuri/uitable"
-	"golang.org/x/term"
 	"gopkg.in/cheggaaa/pb.v1"
 )
 
-var width int
+// pollInterval is how long to wait between two status checks of a transcript.
+// It's read from api.poll_interval when authenticating.
+var pollInterval = DefaultPollInterval
 
-func Transcribe(params S.TranscribeParams, flags S.TranscribeFlags) {
-	Token = GetStoredToken()
-	if Token == "" {
-		printErrorProps := S.PrintErrorProps{
-			Error:   errors.New("No token found"),
-			Message: "Please start by running \033[1m\033[34massemblyai config [token]\033[0m",
+func Transcribe(client *C.Client, params S.TranscribeParams, flags S.TranscribeFlags) {
+	source := params.AudioURL
+	if !isUrl(source) {
+		if path, err := filepath.Abs(source); err == nil {
+			source = path
 		}
-		PrintError(printErrorProps)
-		return
 	}
-
-	checkToken := CheckIfTokenValid()
-	if !checkToken {
-		printErrorProps := S.PrintErrorProps{
-			Error:   errors.New("Invalid token"),
-			Message: INVALID_TOKEN,
-		}
-		PrintError(printErrorProps)
-		return
-	}
-
 	if isUrl(params.AudioURL) {
 		if isYoutubeLink(params.AudioURL) {
 			if isYoutubeShortLink(params.AudioURL) {
@@ -75,7 +61,7 @@ func Transcribe(params S.TranscribeParams, flags S.TranscribeFlags) {
 				PrintError(printErrorProps)
 				return
 			}
-			youtubeVideoURL := YoutubeDownload(youtubeId)
+			youtubeVideoURL := YoutubeDownload(client, youtubeId)
 			if youtubeVideoURL == "" {
 				printErrorProps := S.PrintErrorProps{
 					Error:   errors.New("invalid youtube url"),
@@ -87,7 +73,10 @@ func Transcribe(params S.TranscribeParams, flags S.TranscribeFlags) {
 			params.AudioURL = youtubeVideoURL
 		}
 		if !checkAAICDN(params.AudioURL) {
-			resp, err := http.Get(params.AudioURL)
+			resp, err := NewHTTPClient().Get(params.AudioURL)
+			if err == nil {
+				resp.Body.Close()
+			}
 			if err != nil || resp.StatusCode != 200 {
 				printErrorProps := S.PrintErrorProps{
 					Error:   errors.New("unreachable url"),
@@ -98,7 +87,7 @@ func Transcribe(params S.TranscribeParams, flags S.TranscribeFlags) {
 			}
 		}
 	} else {
-		uploadedURL := UploadFile(params.AudioURL)
+		uploadedURL := UploadFile(client, params.AudioURL)
 		if uploadedURL == "" {
 			printErrorProps := S.PrintErrorProps{
 				Error:   errors.New("invalid file"),
@@ -110,48 +99,30 @@ func Transcribe(params S.TranscribeParams, flags S.TranscribeFlags) {
 		params.AudioURL = uploadedURL
 	}
 
-	paramsJSON, err := json.Marshal(params)
-	if err != nil {
-		printErrorProps := S.PrintErrorProps{
-			Error:   err,
-			Message: "Something went wrong. Please try again.",
-		}
-		PrintError(printErrorProps)
-	}
-
 	TelemetryCaptureEvent("CLI transcription created", nil)
-	body := bytes.NewReader(paramsJSON)
 
-	response := QueryApi("/transcript", "POST", body, nil)
-	var transcriptResponse S.TranscriptResponse
-	if err := json.Unmarshal(response, &transcriptResponse); err != nil {
+	transcriptResponse, err := client.SubmitTranscript(context.Background(), params)
+	if err != nil {
 		printErrorProps := S.PrintErrorProps{
 			Error:   err,
-			Message: "Something went wrong. Please try again.",
-		}
-		PrintError(printErrorProps)
-		return
-	}
-	if transcriptResponse.Error != nil {
-		printErrorProps := S.PrintErrorProps{
-			Error:   errors.New(*transcriptResponse.Error),
-			Message: *transcriptResponse.Error,
+			Message: "We couldn't submit your file for transcription.",
 		}
 		PrintError(printErrorProps)
 		return
 	}
+	RecordSubmitted(source, params, *transcriptResponse)
 	id := transcriptResponse.ID
 	if !flags.Poll {
 		if flags.Json {
-			print := BeutifyJSON(response)
+			print := BeutifyJSON(transcriptResponse.Raw)
 			fmt.Println(string(print))
 			return
 		}
-		fmt.Fprintf(os.Stdin, "Your transcription was created (id %s)\n", *id)
+		fmt.Fprintf(os.Stderr, "Your transcription was created (id %s)\n", *id)
 		return
 	}
 
-	PollTranscription(*id, flags)
+	PollTranscription(client, *id, flags)
 }
 
 func isUrl(str string) bool {
@@ -184,7 +155,7 @@ func checkAAICDN(url string) bool {
 	return strings.HasPrefix(url, "https://cdn.assemblyai.com/")
 }
 
-func UploadFile(path string) string {
+func UploadFile(client *C.Client, path string) string {
 	isAbs := filepath.IsAbs(path)
 	if !isAbs {
 		wd, err := os.Getwd()
@@ -208,176 +179,131 @@ func UploadFile(path string) string {
 		PrintError(printErrorProps)
 		return ""
 	}
+	defer file.Close()
 
 	TelemetryCaptureEvent("CLI upload started", nil)
 
 	fileInfo, _ := file.Stat()
 	bar := pb.New(int(fileInfo.Size()))
-	bar.Output = os.Stdin
+	bar.Output = os.Stderr
 	bar.SetUnits(pb.U_BYTES_DEC)
 	bar.Prefix("Uploading file to our servers: ")
 	bar.ShowBar = false
 	bar.ShowTimeLeft = false
 	bar.Start()
 
-	response := QueryApi("/upload", "POST", bar.NewProxyReader(file), nil)
-
+	uploadURL, err := client.Upload(context.Background(), &progressReader{SectionReader: io.NewSectionReader(file, 0, fileInfo.Size()), bar: bar})
 	bar.Finish()
-	var uploadResponse S.UploadResponse
-	if err := json.Unmarshal(response, &uploadResponse); err != nil {
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't upload your file.",
+		}
+		PrintError(printErrorProps)
 		return ""
 	}
 	TelemetryCaptureEvent("CLI upload ended", nil)
 
-	return uploadResponse.UploadURL
+	return uploadURL
+}
+
+// progressReader reports upload progress on bar. The client reads it through
+// a new io.SectionReader for each attempt, so a retried upload starts over
+// without sharing a read position with the attempt that failed.
+type progressReader struct {
+	*io.SectionReader
+	bar *pb.ProgressBar
 }
 
-func PollTranscription(id string, flags S.TranscribeFlags) {
-	fmt.Fprintln(os.Stdin, "Transcribing file with id "+id)
+func (r *progressReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.SectionReader.ReadAt(p, off)
+	r.bar.Set64(off + int64(n))
+	return n, err
+}
+
+func PollTranscription(client *C.Client, id string, flags S.TranscribeFlags) {
+	fmt.Fprintln(os.Stderr, "Transcribing file with id "+id)
 
 	s := CallSpinner(" Processing time is usually under 60 seconds.")
 
-	for {
-		response := QueryApi("/transcript/"+id, "GET", nil, s)
-		if response == nil {
-			s.Stop()
-			printErrorProps := S.PrintErrorProps{
-				Error:   errors.New("Error getting transcription"),
-				Message: "Something went wrong. Please try again later.",
-			}
-			PrintError(printErrorProps)
-			return
-		}
-		var transcript S.TranscriptResponse
-		if err := json.Unmarshal(response, &transcript); err != nil {
-			printErrorProps := S.PrintErrorProps{
-				Error:   err,
-				Message: "Something went wrong. Please try again.",
-			}
-			PrintError(printErrorProps)
-			s.Stop()
-			return
-		}
-		if transcript.Error != nil {
-			s.Stop()
-			fmt.Println(*transcript.Error)
-			return
+	transcript, err := client.WaitForTranscript(context.Background(), id, pollInterval)
+	s.Stop()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't fetch the transcription. Please try again later.",
 		}
-		if transcript.Status == nil {
-			s.Stop()
-			fmt.Println("Something went wrong. Please try again.")
-			return
+		PrintError(printErrorProps)
+		return
+	}
+	RecordFetched(*transcript)
+	if transcript.Error != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   errors.New(*transcript.Error),
+			Message: *transcript.Error,
 		}
-		if *transcript.Status == "completed" {
-			s.Stop()
-			var properties *S.PostHogProperties = new(S.PostHogProperties)
-			properties.Poll = flags.Poll
-			properties.Json = flags.Json
-
-			properties.AutoChapters = false
-			properties.AutoHighlights = false
-			properties.ContentModeration = false
-			properties.EntityDetection = false
-			properties.FormatText = false
-			properties.Punctuate = false
-			properties.RedactPii = false
-			properties.SentimentAnalysis = false
-			properties.TopicDetection = false
-			if transcript.AutoChapters != nil {
-				properties.AutoChapters = *transcript.AutoChapters
-			}
-			if transcript.AutoHighlights != nil {
-				properties.AutoHighlights = *transcript.AutoHighlights
-			}
-			if transcript.ContentSafety != nil {
-				properties.ContentModeration = *transcript.ContentSafety
-			}
-			if transcript.EntityDetection != nil {
-				properties.EntityDetection = *transcript.EntityDetection
-			}
-			if transcript.FormatText != nil {
-				properties.FormatText = *transcript.FormatText
-			}
-			if transcript.Punctuate != nil {
-				properties.Punctuate = *transcript.Punctuate
-			}
-			if transcript.RedactPii != nil {
-				properties.RedactPii = *transcript.RedactPii
-			}
-			if transcript.SentimentAnalysis != nil {
-				properties.SentimentAnalysis = *transcript.SentimentAnalysis
-			}
-			if transcript.IabCategories != nil {
-				properties.TopicDetection = *transcript.IabCategories
-			}
-			properties.DualChannel = transcript.DualChannel
-			properties.SpeakerLabels = transcript.SpeakerLabels
-
-			TelemetryCaptureEvent("CLI transcription finished", properties)
-
-			if flags.Json {
-				print := BeutifyJSON(response)
-				fmt.Println(string(print))
-				return
-			}
-			getFormattedOutput(transcript, flags)
+		PrintError(printErrorProps)
+		return
+	}
+	if transcript.Status == nil {
+		fmt.Println("Something went wrong. Please try again.")
+		return
+	}
+	if flags.Segmentation != "" {
+		if err := fetchSegments(context.Background(), client, transcript, flags.Segmentation); err != nil {
+			printSegmentsError(err, flags.Segmentation)
 			return
 		}
-		time.Sleep(3 * time.Second)
 	}
-}
+	var properties *S.PostHogProperties = new(S.PostHogProperties)
+	properties.Poll = flags.Poll
+	properties.Json = flags.Json
 
-func getFormattedOutput(transcript S.TranscriptResponse, flags S.TranscribeFlags) {
-	getWidth, _, err := term.GetSize(0)
-	if err != nil {
-		width = 512
-	} else {
-		width = getWidth
-	}
-	fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Transcript")
-	if transcript.SpeakerLabels == true {
-		speakerLabelsPrintFormatted(transcript.Utterances)
-	} else {
-		textPrintFormatted(*transcript.Text, transcript.Words)
+	properties.AutoChapters = false
+	properties.AutoHighlights = false
+	properties.ContentModeration = false
+	properties.EntityDetection = false
+	properties.FormatText = false
+	properties.Punctuate = false
+	properties.RedactPii = false
+	properties.SentimentAnalysis = false
+	properties.TopicDetection = false
+	if transcript.AutoChapters != nil {
+		properties.AutoChapters = *transcript.AutoChapters
 	}
-	if transcript.DualChannel != nil && *transcript.DualChannel == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "\nDual Channel")
-		dualChannelPrintFormatted(transcript.Utterances)
+	if transcript.AutoHighlights != nil {
+		properties.AutoHighlights = *transcript.AutoHighlights
 	}
-	if transcript.AutoHighlights != nil && *transcript.AutoHighlights == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Highlights")
-		highlightsPrintFormatted(transcript.AutoHighlightsResult)
+	if transcript.ContentSafety != nil {
+		properties.ContentModeration = *transcript.ContentSafety
 	}
-	if transcript.ContentSafety != nil && *transcript.ContentSafety == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Content Moderation")
-		contentSafetyPrintFormatted(transcript.ContentSafetyLabels)
+	if transcript.EntityDetection != nil {
+		properties.EntityDetection = *transcript.EntityDetection
 	}
-	if transcript.IabCategories != nil && *transcript.IabCategories == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Topic Detection")
-		topicDetectionPrintFormatted(transcript.IabCategoriesResult)
+	if transcript.FormatText != nil {
+		properties.FormatText = *transcript.FormatText
 	}
-	if transcript.SentimentAnalysis != nil && *transcript.SentimentAnalysis == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Sentiment Analysis")
-		sentimentAnalysisPrintFormatted(transcript.SentimentAnalysisResults)
+	if transcript.Punctuate != nil {
+		properties.Punctuate = *transcript.Punctuate
 	}
-	if transcript.AutoChapters != nil && *transcript.AutoChapters == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Chapters")
-		chaptersPrintFormatted(transcript.Chapters)
+	if transcript.RedactPii != nil {
+		properties.RedactPii = *transcript.RedactPii
 	}
-	if transcript.EntityDetection != nil && *transcript.EntityDetection == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Entity Detection")
-		entityDetectionPrintFormatted(transcript.Entities)
+	if transcript.SentimentAnalysis != nil {
+		properties.SentimentAnalysis = *transcript.SentimentAnalysis
 	}
-	if transcript.Summarization != nil && *transcript.Summarization == true {
-		fmt.Fprintf(os.Stdin, "\033[1m%s\033[0m\n", "Summary")
-		summaryPrintFormatted(*transcript.Summary)
-	}
-	if flags.Srt {
-		srtDownloadTranscript(*transcript.ID, transcript.Words)
+	if transcript.IabCategories != nil {
+		properties.TopicDetection = *transcript.IabCategories
 	}
+	properties.DualChannel = transcript.DualChannel
+	properties.SpeakerLabels = transcript.SpeakerLabels
+
+	TelemetryCaptureEvent("CLI transcription finished", properties)
+
+	WriteOutputs(*transcript, flags)
 }
 
-func textPrintFormatted(text string, words []S.SentimentAnalysisResult) {
+func textPrintFormatted(w io.Writer, width int, text string, words []S.SentimentAnalysisResult) {
 	table := uitable.New()
 	table.Wrap = true
 	table.MaxColWidth = uint(width - 10)
@@ -392,13 +318,13 @@ func textPrintFormatted(text string, words []S.SentimentAnalysisResult) {
 			table.AddRow(stamp, sentence)
 		}
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func dualChannelPrintFormatted(utterances *[]S.SentimentAnalysisResult) {
+func dualChannelPrintFormatted(w io.Writer, width int, utterances *[]S.SentimentAnalysisResult) {
 	if utterances == nil {
-		fmt.Println("Could not retrieve Dual Channel")
+		fmt.Fprintln(w, "Could not retrieve Dual Channel")
 		return
 	}
 
@@ -416,13 +342,13 @@ func dualChannelPrintFormatted(utterances *[]S.SentimentAnalysisResult) {
 			speaker = ""
 		}
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func speakerLabelsPrintFormatted(utterances *[]S.SentimentAnalysisResult) {
+func speakerLabelsPrintFormatted(w io.Writer, width int, utterances *[]S.SentimentAnalysisResult) {
 	if utterances == nil {
-		fmt.Println("Could not retrieve Speaker Labels")
+		fmt.Fprintln(w, "Could not retrieve Speaker Labels")
 		return
 	}
 
@@ -443,13 +369,13 @@ func speakerLabelsPrintFormatted(utterances *[]S.SentimentAnalysisResult) {
 			}
 		}
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func highlightsPrintFormatted(highlights *S.AutoHighlightsResult) {
+func highlightsPrintFormatted(w io.Writer, highlights *S.AutoHighlightsResult) {
 	if highlights == nil || *highlights.Status != "success" {
-		fmt.Println("Could not retrieve highlights")
+		fmt.Fprintln(w, "Could not retrieve highlights")
 		return
 	}
 
@@ -463,13 +389,13 @@ func highlightsPrintFormatted(highlights *S.AutoHighlightsResult) {
 	for _, highlight := range highlights.Results {
 		table.AddRow("| "+strconv.FormatInt(*highlight.Count, 10), highlight.Text)
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func contentSafetyPrintFormatted(labels *S.ContentSafetyLabels) {
+func contentSafetyPrintFormatted(w io.Writer, width int, labels *S.ContentSafetyLabels) {
 	if labels == nil || *labels.Status != "success" {
-		fmt.Println("Could not retrieve content safety labels")
+		fmt.Fprintln(w, "Could not retrieve content safety labels")
 		return
 	}
 	table := uitable.New()
@@ -484,13 +410,13 @@ func contentSafetyPrintFormatted(labels *S.ContentSafetyLabels) {
 		}
 		table.AddRow("| "+labelString, label.Text)
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func topicDetectionPrintFormatted(categories *S.IabCategoriesResult) {
+func topicDetectionPrintFormatted(w io.Writer, width int, categories *S.IabCategoriesResult) {
 	if categories == nil || *categories.Status != "success" {
-		fmt.Println("Could not retrieve topic detection")
+		fmt.Fprintln(w, "Could not retrieve topic detection")
 		return
 	}
 
@@ -512,15 +438,15 @@ func topicDetectionPrintFormatted(categories *S.IabCategoriesResult) {
 	})
 
 	for i, category := range ArrayCategoriesSorted {
-		table.AddRow(fmt.Sprintf("| %o", i+1), category.Category)
+		table.AddRow(fmt.Sprintf("| %d", i+1), category.Category)
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func sentimentAnalysisPrintFormatted(sentiments *[]S.SentimentAnalysisResult) {
+func sentimentAnalysisPrintFormatted(w io.Writer, width int, sentiments *[]S.SentimentAnalysisResult) {
 	if sentiments == nil || len(*sentiments) == 0 {
-		fmt.Println("Could not retrieve sentiment analysis")
+		fmt.Fprintln(w, "Could not retrieve sentiment analysis")
 		return
 	}
 
@@ -533,13 +459,13 @@ func sentimentAnalysisPrintFormatted(sentiments *[]S.SentimentAnalysisResult) {
 		sentimentStatus := sentiment.Sentiment
 		table.AddRow("| "+sentimentStatus, sentiment.Text)
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func chaptersPrintFormatted(chapters *[]S.Chapter) {
+func chaptersPrintFormatted(w io.Writer, width int, chapters *[]S.Chapter) {
 	if chapters == nil || len(*chapters) == 0 {
-		fmt.Println("Could not retrieve chapters")
+		fmt.Fprintln(w, "Could not retrieve chapters")
 		return
 	}
 
@@ -556,13 +482,13 @@ func chaptersPrintFormatted(chapters *[]S.Chapter) {
 		table.AddRow("| Summary", chapter.Summary)
 		table.AddRow("", "")
 	}
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func entityDetectionPrintFormatted(entities *[]S.Entity) {
+func entityDetectionPrintFormatted(w io.Writer, width int, entities *[]S.Entity) {
 	if entities == nil || len(*entities) == 0 {
-		fmt.Println("Could not retrieve entity detection")
+		fmt.Fprintln(w, "Could not retrieve entity detection")
 		return
 	}
 
@@ -584,16 +510,21 @@ func entityDetectionPrintFormatted(entities *[]S.Entity) {
 			entityMap[entity.EntityType] = append(entityMap[entity.EntityType], entity.Text)
 		}
 	}
-	for entityType, entityTexts := range entityMap {
-		table.AddRow("| "+entityType, strings.Join(entityTexts, ", "))
+	entityTypes := make([]string, 0, len(entityMap))
+	for entityType := range entityMap {
+		entityTypes = append(entityTypes, entityType)
 	}
-	fmt.Println(table)
-	fmt.Println()
+	sort.Strings(entityTypes)
+	for _, entityType := range entityTypes {
+		table.AddRow("| "+entityType, strings.Join(entityMap[entityType], ", "))
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 }
 
-func summaryPrintFormatted(summary interface{}) {
+func summaryPrintFormatted(w io.Writer, width int, summary interface{}) {
 	if summary == nil {
-		fmt.Println("Could not retrieve summary")
+		fmt.Fprintln(w, "Could not retrieve summary")
 		return
 	}
 	table := uitable.New()
@@ -616,38 +547,79 @@ func summaryPrintFormatted(summary interface{}) {
 		}
 	}
 
-	fmt.Println(table)
-	fmt.Println()
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
 
 }
 
-func srtDownloadTranscript(id string, words []S.SentimentAnalysisResult) {
-	// Create a new file.
-	f, err := os.Create(fmt.Sprintf("%s.srt", id))
-	if err != nil {
-		fmt.Println("Could not create .srt file, please check your permissions")
-		return
-	}
-	defer f.Close()
-
-	output := GetSrtText(words)
-
-	// Write bytes to file.
-	_, err = f.WriteString(output)
-	if err != nil {
-		fmt.Println("Could not write to .srt file, please check your permissions")
-		f.Close()
-		return
-	}
-
-	fmt.Printf("Successfully created file %s.srt\n", id)
-}
-
 type ArrayCategories struct {
 	Score    float64 `json:"score"`
 	Category string  `json:"category"`
 }
 
+// ValidateParams checks the combinations of transcription parameters the API
+// doesn't support, so they're rejected the same way for a single file and for
+// each row of a batch manifest.
+func ValidateParams(params S.TranscribeParams) error {
+	if params.DualChannel && params.SpeakerLabels {
+		return errors.New("Speaker labels are not supported for dual channel audio")
+	}
+	if params.BoostParam != nil && *params.BoostParam != "" && *params.BoostParam != "low" && *params.BoostParam != "default" && *params.BoostParam != "high" {
+		return errors.New("Please provide a valid boost_param. Valid values are low, default, or high.")
+	}
+	if params.Summarization {
+		if params.AutoChapters {
+			return errors.New("Auto chapters are not supported for summarization")
+		}
+		if _, ok := S.SummarizationTypeMapReverse[params.SummaryType]; !ok {
+			return errors.New("Invalid summary type. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization")
+		}
+		if params.SummaryModel != "" {
+			if _, ok := S.SummarizationModelMap[params.SummaryModel]; !ok {
+				return errors.New("Invalid summary model. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization")
+			}
+			if !Contains(S.SummarizationModelMap[params.SummaryModel], params.SummaryType) {
+				return errors.New("Cant use summary model " + params.SummaryModel + " with summary type " + params.SummaryType + ". To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization")
+			}
+			if params.SummaryModel == "conversational" && !params.SpeakerLabels {
+				return errors.New("Speaker labels are required for conversational summarization. To know more about Summarization, head over to https://assemblyai.com/docs/audio-intelligence#summarization")
+			}
+		}
+	}
+	if params.RedactPii {
+		for _, policy := range params.RedactPiiPolicies {
+			if _, ok := S.PIIRedactionPolicyMap[policy]; !ok {
+				return fmt.Errorf("%s is not a valid policy. See https://www.assemblyai.com/docs/Models/pii_redaction for the complete list of supported policies.", policy)
+			}
+		}
+	} else if len(params.RedactPiiPolicies) > 0 || params.RedactPiiAudio || params.RedactPiiAudioQuality != "" || params.RedactPiiSub != "" {
+		return errors.New("--redact_pii_policies, --redact_pii_audio, --redact_pii_audio_quality and --redact_pii_sub require --redact_pii.")
+	}
+	if params.RedactPiiAudioQuality != "" {
+		if !params.RedactPiiAudio {
+			return errors.New("--redact_pii_audio_quality requires --redact_pii_audio.")
+		}
+		if params.RedactPiiAudioQuality != "mp3" && params.RedactPiiAudioQuality != "wav" {
+			return errors.New("Please provide a valid redact_pii_audio_quality. Valid values are mp3 or wav.")
+		}
+	}
+	if params.RedactPiiSub != "" && params.RedactPiiSub != "hash" && params.RedactPiiSub != "entity_name" {
+		return errors.New("Please provide a valid redact_pii_sub. Valid values are hash or entity_name.")
+	}
+	if params.LanguageCode != nil {
+		if params.LanguageDetection {
+			return errors.New("Please provide either language detection or language code, not both.")
+		}
+		if _, ok := S.LanguageMap[*params.LanguageCode]; !ok {
+			return errors.New("Invalid language code. See https://www.assemblyai.com/docs/Concepts/faq#supported-languages for supported languages.")
+		}
+	}
+	if err := ValidateCustomSpelling(params.CustomSpelling); err != nil {
+		return errors.New("Invalid custom spelling. Please provide a valid custom spelling JSON.")
+	}
+	return nil
+}
+
 func ValidateCustomSpelling(customSpelling []S.CustomSpelling) error {
 	for _, spelling := range customSpelling {
 		if len(spelling.From) == 0 {
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata with the current output.")
+
+func loadTranscript(t *testing.T) S.TranscriptResponse {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "transcript.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transcript S.TranscriptResponse
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatal(err)
+	}
+	transcript.Raw = data
+	return transcript
+}
+
+// assertGolden compares got with testdata/<name>.golden.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Missing golden file, run go test ./utils -update: %v", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("Output differs from %s.\nExpected:\n%s\nGot:\n%s", path, expected, got)
+	}
+}
+
+func TestPrintFormatted(t *testing.T) {
+	width := 100
+	transcript := loadTranscript(t)
+	var chapterSummary interface{} = []interface{}{
+		map[string]interface{}{"headline": "Welcome to the show", "gist": "Welcome", "summary": "The host welcomes the guest."},
+	}
+
+	tests := []struct {
+		name   string
+		render func(w io.Writer)
+	}{
+		{"text", func(w io.Writer) { textPrintFormatted(w, width, *transcript.Text, transcript.Words) }},
+		{"speaker_labels", func(w io.Writer) { speakerLabelsPrintFormatted(w, width, transcript.Utterances) }},
+		{"dual_channel", func(w io.Writer) { dualChannelPrintFormatted(w, width, transcript.Utterances) }},
+		{"segments", func(w io.Writer) { segmentsPrintFormatted(w, width, *transcript.Utterances, false) }},
+		{"segments_no_speakers", func(w io.Writer) {
+			segments := []S.SentimentAnalysisResult{}
+			for _, utterance := range *transcript.Utterances {
+				utterance.Speaker = ""
+				segments = append(segments, utterance)
+			}
+			segmentsPrintFormatted(w, width, segments, false)
+		}},
+		{"highlights", func(w io.Writer) { highlightsPrintFormatted(w, transcript.AutoHighlightsResult) }},
+		{"content_safety", func(w io.Writer) { contentSafetyPrintFormatted(w, width, transcript.ContentSafetyLabels) }},
+		{"topic_detection", func(w io.Writer) { topicDetectionPrintFormatted(w, width, transcript.IabCategoriesResult) }},
+		{"sentiment_analysis", func(w io.Writer) { sentimentAnalysisPrintFormatted(w, width, transcript.SentimentAnalysisResults) }},
+		{"chapters", func(w io.Writer) { chaptersPrintFormatted(w, width, transcript.Chapters) }},
+		{"entity_detection", func(w io.Writer) { entityDetectionPrintFormatted(w, width, transcript.Entities) }},
+		{"summary", func(w io.Writer) { summaryPrintFormatted(w, width, *transcript.Summary) }},
+		{"summary_chapters", func(w io.Writer) { summaryPrintFormatted(w, width, chapterSummary) }},
+		{"unavailable", func(w io.Writer) {
+			speakerLabelsPrintFormatted(w, width, nil)
+			dualChannelPrintFormatted(w, width, nil)
+			highlightsPrintFormatted(w, nil)
+			contentSafetyPrintFormatted(w, width, nil)
+			topicDetectionPrintFormatted(w, width, nil)
+			sentimentAnalysisPrintFormatted(w, width, nil)
+			chaptersPrintFormatted(w, width, nil)
+			entityDetectionPrintFormatted(w, width, nil)
+			summaryPrintFormatted(w, width, nil)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			test.render(&out)
+			assertGolden(t, test.name, out.Bytes())
+		})
+	}
+}
@@ -7,10 +7,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -24,11 +22,13 @@ import (
 	"golang.org/x/term"
 )
 
-var AAIURL = "https://api.assemblyai.com/v2"
 var PH_TOKEN string
 var SENTRY_DNS string
 
 func TelemetryCaptureEvent(event string, properties *S.PostHogProperties) {
+	if Contains(os.Args, "--test") {
+		return
+	}
 	isTelemetryEnabled := GetConfigFileValue("features.telemetry")
 	if isTelemetryEnabled == "true" {
 
@@ -110,7 +110,7 @@ func spinnerMessage(message string) string {
 
 func CallSpinner(message string) *spinner.Spinner {
 	newMessage := spinnerMessage(message)
-	s := spinner.New(spinner.CharSets[7], 100*time.Millisecond, spinner.WithSuffix(newMessage))
+	s := spinner.New(spinner.CharSets[7], 100*time.Millisecond, spinner.WithSuffix(newMessage), spinner.WithWriter(os.Stderr))
 	s.Start()
 	return s
 }
@@ -127,43 +127,11 @@ func PrintError(props S.PrintErrorProps) {
 			}
 		}
 		fmt.Printf("\n%s\n", message)
-		os.Exit(1)
-	}
-}
-
-func QueryApi(path string, method string, body io.Reader, s *spinner.Spinner) []byte {
-	resp, err := http.NewRequest(method, AAIURL+path, body)
-	if err != nil {
-		printErrorProps := S.PrintErrorProps{
-			Error:   err,
-			Message: "Something went wrong. Please try again.",
-		}
-		PrintError(printErrorProps)
-	}
-
-	resp.Header.Add("Accept", "application/json")
-	resp.Header.Add("Authorization", Token)
-	resp.Header.Add("Transfer-Encoding", "chunked")
-
-	response, err := http.DefaultClient.Do(resp)
-	if err != nil {
-		printErrorProps := S.PrintErrorProps{
-			Error:   err,
-			Message: "Something went wrong. Please try again.",
-		}
-		PrintError(printErrorProps)
-	}
-	defer response.Body.Close()
-
-	responseData, err := ioutil.ReadAll(response.Body)
-	if err != nil || response.StatusCode != 200 {
-		printErrorProps := S.PrintErrorProps{
-			Error:   err,
-			Message: "Something went wrong. Please try again.",
+		if detail := errorDetail(err); detail != "" {
+			fmt.Println(detail)
 		}
-		PrintError(printErrorProps)
+		os.Exit(ExitCode(err))
 	}
-	return responseData
 }
 
 func BeutifyJSON(data []byte) []byte {
@@ -297,27 +265,6 @@ func GetSentenceTimestamps(sentences []string, words []S.SentimentAnalysisResult
 	return timestamps
 }
 
-func GetSrtText(words []S.SentimentAnalysisResult) string {
-	srtText := ""
-	index := 1
-	sentence := ""
-	var start string
-	for _, word := range words {
-		if sentence == "" {
-			start = TransformMsToTimestamp(*word.Start, true)
-		}
-		sentence += word.Text + " "
-		if strings.HasSuffix(word.Text, ".") {
-
-			srtText += fmt.Sprintf("%d\n%s --> %s\n%s\n\n", index, start, TransformMsToTimestamp(*word.End, true), sentence)
-			sentence = ""
-			index++
-		}
-	}
-
-	return srtText
-}
-
 func GetSentenceTimestampsAndSpeaker(sentences []string, words []S.SentimentAnalysisResult) [][]string {
 	var lastIndex int
 	timestamps := [][]string{}
@@ -378,11 +325,14 @@ func InitSentry() {
 }
 
 func CheckForUpdates(currentVersion string) {
+	if Contains(os.Args, "--test") {
+		return
+	}
 	terminalWidth, _, err := term.GetSize(0)
 	if err != nil {
 		terminalWidth = 0
 	}
-	resp, err := http.Get("https://api.github.com/repos/assemblyai/assemblyai-cli/releases/latest")
+	resp, err := NewHTTPClient().Get("https://api.github.com/repos/assemblyai/assemblyai-cli/releases/latest")
 	if err != nil {
 		return
 	}
@@ -421,14 +371,14 @@ func CheckForUpdates(currentVersion string) {
 		}
 
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s %s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
 			strings.Repeat("_", boxWidth),
 		)
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s%s%s%s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
@@ -437,7 +387,7 @@ func CheckForUpdates(currentVersion string) {
 			"|",
 		)
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s%s%s%s%s%s%s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
@@ -449,7 +399,7 @@ func CheckForUpdates(currentVersion string) {
 			"|",
 		)
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s%s%s%s%s%s%s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
@@ -461,7 +411,7 @@ func CheckForUpdates(currentVersion string) {
 			"|",
 		)
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s%s%s%s%s%s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
@@ -472,7 +422,7 @@ func CheckForUpdates(currentVersion string) {
 			"|",
 		)
 		fmt.Fprintf(
-			os.Stdin,
+			os.Stderr,
 			"%s%s%s%s%s\n",
 			strings.Repeat(" ", padding),
 			strings.Repeat(" ", paddingExtra),
@@ -0,0 +1,62 @@
+/*
+Copyright © 2022 AssemblyAI support@assemblyai.com
+*/
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	C "github.com/AssemblyAI/assemblyai-cli/client"
+	S "github.com/AssemblyAI/assemblyai-cli/schemas"
+	"github.com/gosuri/uitable"
+)
+
+// SearchWords prints how often each term occurs in a transcript and when,
+// using the word search of the API.
+func SearchWords(client *C.Client, id string, flags S.WordsFlags) {
+	s := CallSpinner(" Searching the transcription...")
+	result, err := client.SearchWords(context.Background(), id, flags.Terms)
+	s.Stop()
+	if err != nil {
+		printErrorProps := S.PrintErrorProps{
+			Error:   err,
+			Message: "We couldn't search the transcription: " + apiErrorMessage(err),
+		}
+		PrintError(printErrorProps)
+		return
+	}
+
+	if flags.Json {
+		data, _ := json.Marshal(result)
+		fmt.Println(string(BeutifyJSON(data)))
+		return
+	}
+	wordsPrintFormatted(os.Stdout, result)
+}
+
+func wordsPrintFormatted(w io.Writer, result *S.WordSearchResponse) {
+	table := uitable.New()
+	table.Wrap = true
+	table.Separator = " |\t"
+	table.AddRow("| count", "text", "timestamps")
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return result.Matches[i].Count > result.Matches[j].Count
+	})
+	for _, match := range result.Matches {
+		timestamps := []string{}
+		for _, timestamp := range match.Timestamps {
+			timestamps = append(timestamps, TransformMsToTimestamp(timestamp[0], false))
+		}
+		table.AddRow("| "+strconv.FormatInt(match.Count, 10), match.Text, strings.Join(timestamps, ", "))
+	}
+	fmt.Fprintln(w, table)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Found %d match(es) in transcription %s.\n", result.TotalCount, result.ID)
+}
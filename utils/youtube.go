@@ -8,16 +8,17 @@ import (
 	"io"
 	"os"
 
+	C "github.com/AssemblyAI/assemblyai-cli/client"
 	S "github.com/AssemblyAI/assemblyai-cli/schemas"
 	"github.com/kkdai/youtube/v2"
 )
 
 var Filename = os.TempDir() + "tmp-video."
 
-func YoutubeDownload(id string) string {
-	client := youtube.Client{}
+func YoutubeDownload(client *C.Client, id string) string {
+	youtubeClient := youtube.Client{HTTPClient: NewHTTPClient()}
 
-	video, err := client.GetVideo(id)
+	video, err := youtubeClient.GetVideo(id)
 	if err != nil {
 		printErrorProps := S.PrintErrorProps{
 			Error:   err,
@@ -27,7 +28,7 @@ func YoutubeDownload(id string) string {
 	}
 
 	formats := video.Formats.WithAudioChannels() // only get videos with audio
-	stream, _, err := client.GetStream(video, &formats[0])
+	stream, _, err := youtubeClient.GetStream(video, &formats[0])
 	if err != nil {
 		printErrorProps := S.PrintErrorProps{
 			Error:   err,
@@ -55,7 +56,7 @@ func YoutubeDownload(id string) string {
 		}
 		PrintError(printErrorProps)
 	}
-	uploadedURL := UploadFile(Filename)
+	uploadedURL := UploadFile(client, Filename)
 	if uploadedURL == "" {
 		printErrorProps := S.PrintErrorProps{
 			Error:   errors.New("The file does not exist. Please try again with a different one."),